@@ -0,0 +1,231 @@
+package fb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// readSignedRequest verifies and decodes the signed_request field of a callback request made by Facebook.
+func readSignedRequest(appSecret string, r *http.Request) (*SignedRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, ErrSignedRequest
+	}
+	sr, err := ParseSignedRequest(appSecret, r.PostFormValue("signed_request"))
+	if err != nil {
+		return nil, err
+	}
+	if sr.UserID == "" {
+		return nil, ErrSignedRequest
+	}
+	return sr, nil
+}
+
+// A DeauthorizeHandler is an http.Handler for the Deauthorize Callback URL of an app, which Facebook calls when a
+// user removes the app.
+// Info: https://developers.facebook.com/docs/facebook-login/manually-build-a-login-flow#deauth-callback
+type DeauthorizeHandler struct {
+	AppSecret string // Used to verify the signed request; must be set.
+
+	// Handle is called with the app-scoped ID of the user. If it returns an error, the handler responds with status
+	// 500. The context is that of the HTTP request.
+	Handle func(ctx context.Context, userID string, sr *SignedRequest) error
+}
+
+// ServeHTTP implements http.Handler.
+func (dh *DeauthorizeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	sr, err := readSignedRequest(dh.AppSecret, r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if dh.Handle != nil {
+		if err := dh.Handle(r.Context(), sr.UserID, sr); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Statuses of a DataDeletion.
+const (
+	DataDeletionPending  = "pending"
+	DataDeletionComplete = "complete"
+	DataDeletionFailed   = "failed" // Set by DataDeletionHandler if Handle returns an error.
+)
+
+// A DataDeletion is a request by a user to delete their data, identified by its confirmation code.
+type DataDeletion struct {
+	ConfirmationCode string    `json:"confirmation_code"`
+	UserID           string    `json:"-"`
+	Status           string    `json:"status"`
+	RequestedAt      time.Time `json:"requested_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ErrDataDeletionNotFound is returned by a DataDeletionStore for an unknown confirmation code.
+var ErrDataDeletionNotFound = errors.New("fb: data deletion request not found")
+
+// A DataDeletionStore keeps the data deletion requests by confirmation code. Update the status of a request with
+// PutDataDeletion once the data is deleted.
+type DataDeletionStore interface {
+	DataDeletion(ctx context.Context, code string) (*DataDeletion, error)
+	PutDataDeletion(ctx context.Context, d *DataDeletion) error
+}
+
+// A MemoryDataDeletionStore is a DataDeletionStore that keeps the requests in memory. The zero value is ready to use.
+type MemoryDataDeletionStore struct {
+	mu sync.Mutex
+	m  map[string]DataDeletion
+}
+
+// DataDeletion implements DataDeletionStore.
+func (s *MemoryDataDeletionStore) DataDeletion(_ context.Context, code string) (*DataDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.m[code]
+	if !ok {
+		return nil, ErrDataDeletionNotFound
+	}
+	return &d, nil
+}
+
+// PutDataDeletion implements DataDeletionStore.
+func (s *MemoryDataDeletionStore) PutDataDeletion(_ context.Context, d *DataDeletion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[string]DataDeletion)
+	}
+	s.m[d.ConfirmationCode] = *d
+	return nil
+}
+
+// A DataDeletionHandler is an http.Handler for the Data Deletion Request URL of an app, which Facebook calls when a
+// user asks for their data to be deleted. For each request, the handler generates a confirmation code, saves a
+// pending DataDeletion in the Store, calls Handle, and responds with the confirmation code and the URL where the user
+// can check the status of the deletion. If Handle returns an error, the DataDeletion is marked as failed and Facebook
+// is given status 500, so that it sends the request again (with a new confirmation code).
+// Info: https://developers.facebook.com/docs/development/create-an-app/app-dashboard/data-deletion-callback
+type DataDeletionHandler struct {
+	AppSecret string            // Used to verify the signed request; must be set.
+	Store     DataDeletionStore // Keeps the status of the requests; must be set.
+
+	// StatusURL is the absolute URL of the status page, such as one served by a DataDeletionStatusHandler. The
+	// confirmation code is added to it as the "code" query parameter. It must be set.
+	StatusURL string
+
+	// Handle is called to start deleting the data of the user with the given app-scoped ID. The deletion may continue
+	// after Handle returns; set the status of the DataDeletion to DataDeletionComplete when it is done. If Handle
+	// returns an error, the DataDeletion is marked as failed and the handler responds with status 500. The context is
+	// that of the HTTP request.
+	Handle func(ctx context.Context, userID, code string, sr *SignedRequest) error
+
+	// OnError, if set, is called with the error of the Store if the DataDeletion could not be marked as failed.
+	OnError func(err error)
+}
+
+// ServeHTTP implements http.Handler.
+func (dh *DataDeletionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	sr, err := readSignedRequest(dh.AppSecret, r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	statusURL, err := url.Parse(dh.StatusURL)
+	if err != nil || !statusURL.IsAbs() || dh.Store == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	code, err := newConfirmationCode()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	d := &DataDeletion{ConfirmationCode: code, UserID: sr.UserID, Status: DataDeletionPending, RequestedAt: now, UpdatedAt: now}
+	if err := dh.Store.PutDataDeletion(r.Context(), d); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if dh.Handle != nil {
+		if err := dh.Handle(r.Context(), sr.UserID, code, sr); err != nil {
+			d.Status = DataDeletionFailed
+			d.UpdatedAt = time.Now()
+			if err := dh.Store.PutDataDeletion(r.Context(), d); err != nil && dh.OnError != nil {
+				dh.OnError(err)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	q := statusURL.Query()
+	q.Set("code", code)
+	statusURL.RawQuery = q.Encode()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		URL              string `json:"url"`
+		ConfirmationCode string `json:"confirmation_code"`
+	}{statusURL.String(), code})
+}
+
+// newConfirmationCode generates a random confirmation code for a data deletion request.
+func newConfirmationCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// A DataDeletionStatusHandler is an http.Handler that serves the status of the data deletion request given by the
+// "code" query parameter as JSON. It can serve the StatusURL of a DataDeletionHandler.
+type DataDeletionStatusHandler struct {
+	Store DataDeletionStore // Must be set.
+}
+
+// ServeHTTP implements http.Handler.
+func (sh *DataDeletionStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	code := r.URL.Query().Get("code")
+	if sh.Store == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if code == "" {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	d, err := sh.Store.DataDeletion(r.Context(), code)
+	if errors.Is(err, ErrDataDeletionNotFound) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
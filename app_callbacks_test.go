@@ -0,0 +1,136 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func signedRequestForm(signedRequest string) *http.Request {
+	body := url.Values{"signed_request": {signedRequest}}.Encode()
+	r := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestDeauthorizeHandler(t *testing.T) {
+	var got string
+	dh := &DeauthorizeHandler{
+		AppSecret: "secret",
+		Handle: func(_ context.Context, userID string, _ *SignedRequest) error {
+			got = userID
+			return nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signRequest("secret", `{"algorithm":"HMAC-SHA256","user_id":"218471"}`)))
+	if rec.Code != http.StatusOK || got != "218471" {
+		t.Errorf("got status %d and user ID %q", rec.Code, got)
+	}
+
+	got = ""
+	rec = httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signRequest("other", `{"algorithm":"HMAC-SHA256","user_id":"218471"}`)))
+	if rec.Code != http.StatusBadRequest || got != "" {
+		t.Errorf("got status %d and user ID %q for a wrong signature", rec.Code, got)
+	}
+}
+
+func TestDataDeletionHandler(t *testing.T) {
+	store := new(MemoryDataDeletionStore)
+	var gotUser, gotCode string
+	dh := &DataDeletionHandler{
+		AppSecret: "secret",
+		Store:     store,
+		StatusURL: "https://example.com/deletion",
+		Handle: func(_ context.Context, userID, code string, _ *SignedRequest) error {
+			gotUser, gotCode = userID, code
+			return nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signRequest("secret", `{"algorithm":"HMAC-SHA256","user_id":"218471"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	var resp struct {
+		URL              string `json:"url"`
+		ConfirmationCode string `json:"confirmation_code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ConfirmationCode == "" || resp.ConfirmationCode != gotCode || gotUser != "218471" {
+		t.Errorf("got response %+v, user ID %q and code %q", resp, gotUser, gotCode)
+	}
+	if resp.URL != "https://example.com/deletion?code="+resp.ConfirmationCode {
+		t.Errorf("got status URL %q", resp.URL)
+	}
+
+	sh := &DataDeletionStatusHandler{Store: store}
+	rec = httptest.NewRecorder()
+	sh.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deletion?code="+resp.ConfirmationCode, nil))
+	var status DataDeletion
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || status.Status != DataDeletionPending || status.ConfirmationCode != resp.ConfirmationCode {
+		t.Errorf("got status %d and body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	sh.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deletion?code=unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for an unknown code", rec.Code)
+	}
+}
+
+func TestDataDeletionHandler_Failure(t *testing.T) {
+	store := new(MemoryDataDeletionStore)
+	var code string
+	dh := &DataDeletionHandler{
+		AppSecret: "secret",
+		Store:     store,
+		StatusURL: "https://example.com/deletion",
+		Handle: func(_ context.Context, _, c string, _ *SignedRequest) error {
+			code = c
+			return errors.New("database unavailable")
+		},
+	}
+	signed := signRequest("secret", `{"algorithm":"HMAC-SHA256","user_id":"218471"}`)
+
+	rec := httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signed))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d", rec.Code)
+	}
+	d, err := store.DataDeletion(context.Background(), code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != DataDeletionFailed {
+		t.Errorf("got status %q for a request whose Handle failed", d.Status)
+	}
+
+	// A handler without a Store or without an absolute StatusURL fails without panicking.
+	dh.Store = nil
+	rec = httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signed))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d without a Store", rec.Code)
+	}
+	dh.Store = store
+	dh.StatusURL = ""
+	rec = httptest.NewRecorder()
+	dh.ServeHTTP(rec, signedRequestForm(signed))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d without a StatusURL", rec.Code)
+	}
+}
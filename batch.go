@@ -0,0 +1,182 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MaxBatchSize is the maximum number of requests that Facebook accepts in one batch.
+const MaxBatchSize = 50
+
+// ErrBatchSize is returned when a batch is empty or has more than MaxBatchSize requests.
+var ErrBatchSize = errors.New("fb: a batch must have from 1 to 50 requests")
+
+// A BatchItem is one request in a batch.
+// Info: https://developers.facebook.com/docs/graph-api/making-multiple-requests
+type BatchItem struct {
+	Req *http.Request // Set up with Req or any of the functions named with a Req suffix.
+
+	// Name is optional. It lets the other requests in the batch refer to the result of this one with BatchResult.
+	Name string
+
+	// DependsOn is optional. It is the Name of a request that must complete before this one.
+	DependsOn string
+
+	// By default, Facebook does not give the response of a named request that another request depends on. Set
+	// KeepResponse to get the response anyway.
+	KeepResponse bool
+}
+
+// A BatchResponse is the response to one request in a batch.
+type BatchResponse struct {
+	Code   int // The HTTP status code.
+	Header http.Header
+	Body   []byte
+}
+
+// Decode decodes the body of the response into v (which may be nil) just like DecodeResponse does for an
+// http.Response, returning an *ErrResponse if Facebook replied with an error.
+func (br *BatchResponse) Decode(v interface{}) error {
+	return decodeBody(br.Code, br.Header, br.Body, v)
+}
+
+// BatchResult gives a reference to the result of the named request in the same batch, which can be used as a
+// parameter value of a request that depends on it. The jsonPath selects the values, such as "$.data.*.id".
+func BatchResult(name, jsonPath string) string {
+	return "{result=" + name + ":" + jsonPath + "}"
+}
+
+// batchResultRef matches a BatchResult reference once it has been URL-encoded.
+var batchResultRef = regexp.MustCompile(`%7Bresult%3D.*?%7D`)
+
+// unescapeBatchResults reverts the URL encoding of the BatchResult references in s so that Facebook finds them.
+func unescapeBatchResults(s string) string {
+	return batchResultRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if u, err := url.QueryUnescape(ref); err == nil {
+			return u
+		}
+		return ref
+	})
+}
+
+type batchOp struct {
+	Method                string `json:"method"`
+	RelativeURL           string `json:"relative_url"`
+	Body                  string `json:"body,omitempty"`
+	Name                  string `json:"name,omitempty"`
+	DependsOn             string `json:"depends_on,omitempty"`
+	OmitResponseOnSuccess *bool  `json:"omit_response_on_success,omitempty"`
+}
+
+// batchOp encodes the item the way Facebook expects requests in the batch parameter. The request is adapted to the
+// client with Rebase, and the base path of the client is trimmed from its URL path.
+func (c *Client) batchOp(item *BatchItem) (*batchOp, error) {
+	if item == nil || item.Req == nil {
+		return nil, errors.New("fb: batch item without a request")
+	}
+	r := c.Rebase(item.Req)
+	op := &batchOp{
+		Method:      r.Method,
+		RelativeURL: strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(c.baseURL().Path, "/")), "/"),
+		Name:        item.Name,
+		DependsOn:   item.DependsOn,
+	}
+	if r.URL.RawQuery != "" {
+		op.RelativeURL += "?" + unescapeBatchResults(r.URL.RawQuery)
+	}
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		op.Body = unescapeBatchResults(string(b))
+	}
+	if item.KeepResponse {
+		op.OmitResponseOnSuccess = new(bool)
+	}
+	return op, nil
+}
+
+// BatchReq sets up a request that runs all of the items in one call to the Graph API. The accessToken is used for
+// the items that do not have their own access token. Use DoBatch to run the request and read the responses.
+func (c *Client) BatchReq(accessToken string, items []*BatchItem) (*http.Request, error) {
+	if len(items) == 0 || len(items) > MaxBatchSize {
+		return nil, ErrBatchSize
+	}
+	ops := make([]*batchOp, len(items))
+	for i, item := range items {
+		op, err := c.batchOp(item)
+		if err != nil {
+			return nil, err
+		}
+		ops[i] = op
+	}
+	b, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	return c.Req(http.MethodPost, "", accessToken, nil,
+		&ParamStrStr{"batch", string(b)},
+		&ParamStrStr{"include_headers", "true"}), nil
+}
+
+// DoBatch runs the items in one batch request and gives the responses in the same order as the items. A response is
+// nil if Facebook did not give one, which is the case for a named request that another request depends on (unless
+// KeepResponse is set) and for a request whose dependency failed. The error returned is about the batch request as
+// a whole; use the Decode method of each BatchResponse to get the result of the corresponding request.
+func (c *Client) DoBatch(ctx context.Context, accessToken string, items []*BatchItem) ([]*BatchResponse, error) {
+	req, err := c.BatchReq(accessToken, items)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var results []*struct {
+		Code    int `json:"code"`
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+		Body string `json:"body"`
+	}
+	if err := c.DecodeResponse(resp, &results); err != nil {
+		return nil, err
+	}
+	responses := make([]*BatchResponse, len(items))
+	for i, res := range results {
+		if i >= len(responses) {
+			break
+		}
+		if res == nil {
+			continue
+		}
+		br := &BatchResponse{
+			Code:   res.Code,
+			Header: make(http.Header, len(res.Headers)),
+			Body:   []byte(res.Body),
+		}
+		for _, h := range res.Headers {
+			br.Header.Add(h.Name, h.Value)
+		}
+		responses[i] = br
+	}
+	return responses, nil
+}
+
+// BatchReq sets up a batch request using DefaultClient.
+func BatchReq(accessToken string, items []*BatchItem) (*http.Request, error) {
+	return DefaultClient.BatchReq(accessToken, items)
+}
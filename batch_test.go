@@ -0,0 +1,96 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClient_DoBatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		var ops []batchOp
+		if err := json.Unmarshal([]byte(r.PostForm.Get("batch")), &ops); err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []batchOp{
+			{Method: "GET", RelativeURL: "v2.12/me/accounts?access_token=abc&fields=id", Name: "pages"},
+			{Method: "GET", RelativeURL: "v2.12/?access_token=abc&ids={result=pages:$.data.*.id}", DependsOn: "pages"},
+			{Method: "POST", RelativeURL: "v2.12/123/subscribed_apps", Body: "access_token=xyz"},
+		}
+		if len(ops) != len(expected) {
+			t.Errorf("got %d operations", len(ops))
+			return
+		}
+		for i := range ops {
+			if ops[i] != expected[i] {
+				t.Errorf("got operation %+v; expected %+v", ops[i], expected[i])
+			}
+		}
+		w.Write([]byte(`[null,{"code":200,"headers":[{"name":"Content-Type","value":"application/json"}],` +
+			`"body":"{\"123\":{\"id\":\"123\"}}"},{"code":400,"headers":[],` +
+			`"body":"{\"error\":{\"message\":\"Permissions error\",\"code\":200}}"}]`))
+	})
+
+	items := []*BatchItem{
+		{Req: c.Req(http.MethodGet, "me/accounts", "abc", []string{"id"}), Name: "pages"},
+		{Req: c.Req(http.MethodGet, "", "abc", nil, &ParamStrStr{"ids", BatchResult("pages", "$.data.*.id")}), DependsOn: "pages"},
+		{Req: c.Req(http.MethodPost, "123/subscribed_apps", "xyz", nil)},
+	}
+	responses, err := c.DoBatch(context.Background(), "abc", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 3 || responses[0] != nil {
+		t.Fatalf("got responses %v", responses)
+	}
+	if responses[1].Header.Get("Content-Type") != "application/json" {
+		t.Errorf("got headers %v", responses[1].Header)
+	}
+	var pages map[string]struct {
+		ID string `json:"id"`
+	}
+	if err := responses[1].Decode(&pages); err != nil || pages["123"].ID != "123" {
+		t.Errorf("got %v and error %v", pages, err)
+	}
+	if err := responses[2].Decode(nil); !IsPermissionDenied(err) {
+		t.Errorf("got error %v", err)
+	}
+
+	if _, err := c.BatchReq("abc", make([]*BatchItem, MaxBatchSize+1)); err != ErrBatchSize {
+		t.Errorf("got error %v for a batch that is too large", err)
+	}
+}
+
+func TestClient_BatchReq_Rebase(t *testing.T) {
+	c := &Client{Version: "v3.0", AppSecret: "secret"}
+	req, err := c.BatchReq("abc", []*BatchItem{{Req: FormLeadsReq("xyz", "f1")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	var ops []batchOp
+	if err := json.Unmarshal([]byte(req.PostForm.Get("batch")), &ops); err != nil {
+		t.Fatal(err)
+	}
+	proof, _ := AppsecretProof("xyz", "secret")
+	u, err := url.Parse(ops[0].RelativeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "v3.0/f1/leads" || u.Query().Get("appsecret_proof") != proof {
+		t.Errorf("got relative URL %q", ops[0].RelativeURL)
+	}
+
+	if _, err := c.BatchReq("abc", []*BatchItem{{Name: "empty"}}); err == nil {
+		t.Error("expected an error for an item without a request")
+	}
+}
@@ -0,0 +1,271 @@
+package fb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultVersion is the Graph API version used by a Client that does not specify its own.
+const DefaultVersion = "v2.12"
+
+// DefaultBaseURL is the Graph API host used by a Client that does not specify its own.
+var DefaultBaseURL = &url.URL{Scheme: "https", Host: "graph.facebook.com"}
+
+// DefaultClient is the Client used by the package-level functions Req, ReqDo, and ReadResponse.
+var DefaultClient = &Client{}
+
+// defaultHTTPClient is used by a Client that has a nil HTTPClient. It is shared so that connections are reused.
+var defaultHTTPClient = &http.Client{Timeout: time.Second * 12}
+
+// A Client holds the settings used to set up and run requests to the Graph API. The zero value is ready to use and
+// sends requests to DefaultBaseURL with DefaultVersion. A Client is safe for concurrent use as long as its fields
+// are not modified while in use.
+type Client struct {
+	BaseURL     *url.URL     // The scheme, host, and optional path prefix; if nil, DefaultBaseURL is used.
+	Version     string       // The Graph API version, such as "v2.12"; if empty, DefaultVersion is used.
+	HTTPClient  *http.Client // If nil, a shared client with a request timeout of 12 seconds is used.
+	AccessToken string       // Used for requests that are given an empty access token.
+
+	// If AppSecret is set, every request set up by the client that has an access token gets an appsecret_proof
+	// parameter computed from the token, as required for apps that have "Require App Secret" enabled.
+	AppSecret string
+
+	// If Throttler is set, requests are delayed as the rate limit usage reported by Facebook approaches 100 percent.
+	Throttler *Throttler
+
+	// If Retry is set, requests that fail in a way that may be temporary are retried according to the policy.
+	Retry *RetryPolicy
+}
+
+// WithVersion returns a copy of the client that uses the given Graph API version, so that a call site can be pinned
+// to a version other than the one the rest of the application uses.
+func (c *Client) WithVersion(version string) *Client {
+	cc := *c
+	cc.Version = version
+	return &cc
+}
+
+func (c *Client) baseURL() *url.URL {
+	if c.BaseURL != nil {
+		return c.BaseURL
+	}
+	return DefaultBaseURL
+}
+
+func (c *Client) version() string {
+	if c.Version != "" {
+		return c.Version
+	}
+	return DefaultVersion
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// Req sets up a request to the Graph API but does not run it. The method should be one of GET, POST, or DELETE.
+// The nodeEdge parameter should not have a leading slash or the Graph API version. If accessToken is empty, the
+// client's AccessToken is used. Leave the fields slice empty or nil to not specify a fields parameter.
+func (c *Client) Req(method, nodeEdge string, accessToken string, fields []string, params ...Param) *http.Request {
+	base := c.baseURL()
+	r := &http.Request{
+		Method: method,
+		URL: &url.URL{
+			Scheme: base.Scheme,
+			Host:   base.Host,
+			Path:   strings.TrimSuffix(base.Path, "/") + "/" + c.version() + "/" + nodeEdge,
+		},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       base.Host,
+	}
+	if accessToken == "" {
+		accessToken = c.AccessToken
+	}
+	if len(fields) > 0 {
+		params = append(params, &ParamStrStr{
+			K: "fields",
+			V: strings.Join(fields, ","),
+		})
+	}
+	if method == http.MethodPost {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		setFormBody(r, encodeParams(accessToken, c.AppSecret, params))
+	} else {
+		r.URL.RawQuery = encodeParams(accessToken, c.AppSecret, params)
+	}
+	return r
+}
+
+// setFormBody sets the URL-encoded form as the body of r.
+func setFormBody(r *http.Request, form string) {
+	buf := []byte(form)
+	r.ContentLength = int64(len(buf))
+	r.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf)), nil
+	}
+}
+
+// Rebase adapts a request set up by one of the package-level functions with a Req suffix, which use DefaultClient,
+// to the client: the request is sent to the client's base URL and version, gets the client's AccessToken if it has no
+// access token, and gets an appsecret_proof computed with the client's AppSecret. This way, all the request builders
+// of the package can be used with any Client:
+//
+//	resp, err := client.Do(client.Rebase(fb.FormLeadsReq(pageAccessToken, formID)))
+//
+// The URL of a request that was not set up by DefaultClient is kept. The request given is not modified.
+func (c *Client) Rebase(req *http.Request) *http.Request {
+	d := DefaultClient
+	if c == d {
+		return req
+	}
+	rc := req.Clone(req.Context())
+	prefix := strings.TrimSuffix(d.baseURL().Path, "/") + "/" + d.version() + "/"
+	if rc.URL.Host == d.baseURL().Host && strings.HasPrefix(rc.URL.Path, prefix) {
+		base := c.baseURL()
+		u := *rc.URL
+		u.Scheme = base.Scheme
+		u.Host = base.Host
+		u.Path = strings.TrimSuffix(base.Path, "/") + "/" + c.version() + "/" + strings.TrimPrefix(rc.URL.Path, prefix)
+		u.RawPath = ""
+		rc.URL = &u
+		rc.Host = base.Host
+	}
+	if c.AccessToken == "" && c.AppSecret == "" {
+		return rc
+	}
+	if rc.Method == http.MethodPost && rc.GetBody != nil {
+		body, err := rc.GetBody()
+		if err != nil {
+			return rc
+		}
+		b, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return rc
+		}
+		v, err := url.ParseQuery(string(b))
+		if err != nil {
+			return rc
+		}
+		c.setCredentials(v)
+		setFormBody(rc, v.Encode())
+	} else {
+		v := rc.URL.Query()
+		c.setCredentials(v)
+		rc.URL.RawQuery = v.Encode()
+	}
+	return rc
+}
+
+// setCredentials sets the access token and appsecret_proof of the client in v.
+func (c *Client) setCredentials(v url.Values) {
+	if v.Get("access_token") == "" && c.AccessToken != "" {
+		v.Set("access_token", c.AccessToken)
+	}
+	if c.AppSecret != "" && c.AppSecret != DefaultClient.AppSecret {
+		v.Del("appsecret_proof")
+	}
+	setAppsecretProof(v, c.AppSecret)
+}
+
+// ReqContext is like Req but sets up the request with the given context, which controls the cancellation and
+// deadline of the request when it is run.
+func (c *Client) ReqContext(ctx context.Context, method, nodeEdge string, accessToken string, fields []string,
+	params ...Param) *http.Request {
+	return c.Req(method, nodeEdge, accessToken, fields, params...).WithContext(ctx)
+}
+
+// Do runs the request using the client's HTTPClient, applying the client's Retry policy and Throttler if set.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.Retry != nil {
+		return c.Retry.do(c, req)
+	}
+	return c.do(req)
+}
+
+// do runs the request once, waiting first as long as the Throttler says.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Throttler == nil {
+		return c.httpClient().Do(req)
+	}
+	token := requestToken(req)
+	if err := c.Throttler.Wait(req.Context(), token); err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := ParseUsage(resp.Header); err == nil {
+		c.Throttler.Observe(token, u)
+	}
+	return resp, nil
+}
+
+// DoContext runs the request with the given context, which replaces any context the request already has. Use it to
+// run a request set up by any of the functions named with a Req suffix.
+func (c *Client) DoContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.Do(req.WithContext(ctx))
+}
+
+// ReqDo uses Req to set up the request and then runs Do on it.
+func (c *Client) ReqDo(method, nodeEdge string, accessToken string, fields []string, params ...Param) (*http.Response, error) {
+	return c.Do(c.Req(method, nodeEdge, accessToken, fields, params...))
+}
+
+// ReqDoContext is like ReqDo but runs the request with the given context.
+func (c *Client) ReqDoContext(ctx context.Context, method, nodeEdge string, accessToken string, fields []string,
+	params ...Param) (*http.Response, error) {
+	return c.Do(c.ReqContext(ctx, method, nodeEdge, accessToken, fields, params...))
+}
+
+// ReadResponse reads the response and decodes it into v, just like the package-level ReadResponse.
+func (c *Client) ReadResponse(res *http.Response, v interface{}) error {
+	return ReadResponse(res, v)
+}
+
+// DecodeResponse reads the response and decodes it into v, returning an *ErrResponse if Facebook replied with an
+// error, just like the package-level DecodeResponse.
+func (c *Client) DecodeResponse(res *http.Response, v interface{}) error {
+	return DecodeResponse(res, v)
+}
+
+// NextPage makes a request to nextURL, which should be a "next" or "previous" paging URL given by Facebook.
+// If the client has an AppSecret, the appsecret_proof parameter is added to the URL if it is missing.
+func (c *Client) NextPage(nextURL string) (*http.Response, error) {
+	return c.NextPageContext(context.Background(), nextURL)
+}
+
+// NextPageContext is like NextPage but sends the request with the given context. The request is sent with
+// DoContext, so the Throttler and Retry policy of the client apply.
+func (c *Client) NextPageContext(ctx context.Context, nextURL string) (*http.Response, error) {
+	req, err := nextPageReq(nextURL, c.AppSecret)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoContext(ctx, req)
+}
+
+// DebugTokenContext sends a token debug request to Facebook with the given context and reads the response.
+func (c *Client) DebugTokenContext(ctx context.Context, accessToken, tokenToDebug string) (*TokenDebug, error) {
+	resp, err := c.DoContext(ctx, c.Rebase(DebugTokenReq(accessToken, tokenToDebug)))
+	if err != nil {
+		return nil, err
+	}
+	info := new(TokenDebug)
+	err = c.ReadResponse(resp, info)
+	return info, err
+}
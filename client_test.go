@@ -0,0 +1,183 @@
+package fb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newTestClient starts a server with the handler and gives a client that sends its requests to the server. The
+// server is closed when the test ends.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	base, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{BaseURL: base, HTTPClient: srv.Client()}
+}
+
+func TestClient_Req(t *testing.T) {
+	base, _ := url.Parse("http://localhost:8080/graph/")
+	c := &Client{BaseURL: base, Version: "v3.0", AccessToken: "default-token"}
+	cases := []struct {
+		Client *Client
+		Token  string
+		URL    string
+	}{
+		{
+			Client: &Client{},
+			Token:  "abc",
+			URL:    "https://graph.facebook.com/" + DefaultVersion + "/me?access_token=abc&fields=id%2Cname",
+		},
+		{
+			Client: c,
+			Token:  "",
+			URL:    "http://localhost:8080/graph/v3.0/me?access_token=default-token&fields=id%2Cname",
+		},
+		{
+			Client: c.WithVersion("v3.1"),
+			Token:  "abc",
+			URL:    "http://localhost:8080/graph/v3.1/me?access_token=abc&fields=id%2Cname",
+		},
+	}
+	for i, tc := range cases {
+		r := tc.Client.Req(http.MethodGet, "me", tc.Token, []string{"id", "name"})
+		if got := r.URL.String(); got != tc.URL {
+			t.Errorf("case %d: got URL %q; expected %q", i, got, tc.URL)
+		}
+	}
+	if c.Version != "v3.0" {
+		t.Errorf("WithVersion modified the original client")
+	}
+}
+
+func TestClient_Rebase(t *testing.T) {
+	base, _ := url.Parse("http://localhost:8080/graph/")
+	c := &Client{BaseURL: base, Version: "v3.0", AppSecret: "secret"}
+	proof, _ := AppsecretProof("abc", "secret")
+
+	r := c.Rebase(FormLeadsReq("abc", "123"))
+	expected := "http://localhost:8080/graph/v3.0/123/leads?access_token=abc&appsecret_proof=" + proof +
+		"&fields=created_time%2Cid%2Cform_id%2Cfield_data"
+	if got := r.URL.String(); got != expected {
+		t.Errorf("got URL %q; expected %q", got, expected)
+	}
+
+	orig := SubscribeAppToPageReq("abc", "123")
+	r = c.Rebase(orig)
+	if got := r.URL.String(); got != "http://localhost:8080/graph/v3.0/123/subscribed_apps" {
+		t.Errorf("got URL %q for a POST", got)
+	}
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if r.PostForm.Get("access_token") != "abc" || r.PostForm.Get("appsecret_proof") != proof {
+		t.Errorf("got form %v", r.PostForm)
+	}
+	if orig.URL.Host != "graph.facebook.com" {
+		t.Error("the original request was modified")
+	}
+
+	// A request set up by the client itself is kept as it is.
+	r = c.Req(http.MethodGet, "me", "abc", nil)
+	if got := c.Rebase(r).URL.String(); got != r.URL.String() {
+		t.Errorf("got URL %q; expected %q", got, r.URL.String())
+	}
+}
+
+func TestClient_ReqDo(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+DefaultVersion+"/me" {
+			t.Errorf("got path %q", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if tok := r.PostForm.Get("access_token"); tok != "abc" {
+			t.Errorf("got access token %q", tok)
+		}
+		w.Write([]byte(`{"id":"123","name":"Some One"}`))
+	})
+
+	resp, err := c.ReqDo(http.MethodPost, "me", "abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := new(GraphResponseMe)
+	if err := c.ReadResponse(resp, me); err != nil {
+		t.Fatal(err)
+	}
+	if me.ID != "123" || me.Name != "Some One" {
+		t.Errorf("got unexpected response %+v", *me)
+	}
+}
+
+func TestClient_ReqDoContext(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not have been sent")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.ReqDoContext(ctx, http.MethodGet, "me", "abc", nil); err == nil {
+		t.Error("expected an error with a canceled context")
+	}
+}
+
+func TestClient_AppsecretProof(t *testing.T) {
+	c := &Client{AppSecret: "secret"}
+	proof, err := AppsecretProof("abc", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.Req(http.MethodGet, "me", "abc", nil)
+	if got := r.URL.Query().Get("appsecret_proof"); got != proof {
+		t.Errorf("GET: got proof %q; expected %q", got, proof)
+	}
+
+	r = c.Req(http.MethodPost, "me", "abc", nil)
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.PostForm.Get("appsecret_proof"); got != proof {
+		t.Errorf("POST: got proof %q; expected %q", got, proof)
+	}
+
+	r = c.Req(http.MethodGet, "me", "abc", nil, &ParamStrStr{"appsecret_proof", "given"})
+	if got := r.URL.Query().Get("appsecret_proof"); got != "given" {
+		t.Errorf("got proof %q; expected the given proof to be kept", got)
+	}
+
+	r = (&Client{}).Req(http.MethodGet, "me", "abc", nil)
+	if got := r.URL.Query().Get("appsecret_proof"); got != "" {
+		t.Errorf("got proof %q without an app secret", got)
+	}
+}
+
+func TestNextPage_AppsecretProof(t *testing.T) {
+	proof, err := AppsecretProof("abc", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("appsecret_proof"); got != proof {
+			t.Errorf("got proof %q; expected %q", got, proof)
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	defer func(appSecret string) { DefaultClient.AppSecret = appSecret }(DefaultClient.AppSecret)
+	DefaultClient.AppSecret = "secret"
+	resp, err := NextPage(c.BaseURL.String()+"/v2.12/me/accounts?access_token=abc&after=c1", c.HTTPClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
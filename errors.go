@@ -0,0 +1,147 @@
+package fb
+
+import "errors"
+
+// Error codes and subcodes documented for the Graph API.
+// Info: https://developers.facebook.com/docs/graph-api/using-graph-api/error-handling
+const (
+	CodeUnknown           = 1   // API Unknown; possibly a temporary issue due to downtime.
+	CodeService           = 2   // API Service; temporary issue due to downtime.
+	CodeTooManyCalls      = 4   // API Too Many Calls; the app is being rate limited.
+	CodePermissionDenied  = 10  // API Permission Denied; the permission was not granted or has been removed.
+	CodeUserTooManyCalls  = 17  // API User Too Many Calls; the user is being rate limited.
+	CodePageTooManyCalls  = 32  // Page-level rate limiting.
+	CodeSession           = 102 // API Session; the login status or access token has expired or been revoked.
+	CodeAccessToken       = 190 // Access token has expired or is invalid.
+	CodeAppLimitReached   = 341 // Application limit reached.
+	CodeDuplicatePost     = 506 // Duplicate post; the same message was posted twice in a row.
+	CodeCustomRateLimited = 613 // Calls to this API have exceeded the rate limit.
+
+	// Permission errors take the codes from 200 to 299.
+	CodePermissionMin = 200
+	CodePermissionMax = 299
+
+	// Business Use Case rate limiting errors take the codes from 80000 to 80014.
+	CodeBusinessUseCaseMin = 80000
+	CodeBusinessUseCaseMax = 80014
+)
+
+// Error subcodes given with CodeAccessToken and CodeSession errors.
+const (
+	SubcodeAppNotInstalled     = 458 // The user has not logged into the app; reauthenticate the user.
+	SubcodeUserCheckpointed    = 459 // The user needs to log in at www.facebook.com or m.facebook.com.
+	SubcodePasswordChanged     = 460 // The user changed the password and must log in again.
+	SubcodeExpired             = 463 // The access token has expired.
+	SubcodeUnconfirmedUser     = 464 // The user needs to log in at www.facebook.com or m.facebook.com.
+	SubcodeInvalidAccessToken  = 467 // The access token is invalid.
+	SubcodeCheckpointedBlocked = 490 // The user is enrolled in a blocking, logged-in checkpoint.
+)
+
+// An errClass is a category of Graph API errors. The sentinel error values are of this type so that errors.Is
+// reports whether an *ErrResponse belongs to the category.
+type errClass struct {
+	msg   string
+	match func(er *ErrResponse) bool
+}
+
+func (ec *errClass) Error() string { return ec.msg }
+
+// Sentinel errors to use with errors.Is, which reports whether an *ErrResponse (or an error wrapping one) belongs to
+// the category. The sentinels are never returned themselves.
+var (
+	ErrTokenExpired       error = &errClass{"fb: access token expired", (*ErrResponse).tokenExpired}
+	ErrTokenInvalid       error = &errClass{"fb: access token invalid", (*ErrResponse).tokenInvalid}
+	ErrPermissionDenied   error = &errClass{"fb: permission denied", (*ErrResponse).permissionDenied}
+	ErrRateLimited        error = &errClass{"fb: rate limited", (*ErrResponse).rateLimited}
+	ErrTransient          error = &errClass{"fb: transient error", (*ErrResponse).transient}
+	ErrUserActionRequired error = &errClass{"fb: user action required", (*ErrResponse).userActionRequired}
+	ErrDuplicatePost      error = &errClass{"fb: duplicate post", (*ErrResponse).duplicatePost}
+)
+
+// Is says if the error belongs to the category of the target, which should be one of the sentinel errors such as
+// ErrTokenExpired. This method lets errors.Is classify an *ErrResponse. A nil *ErrResponse belongs to no category.
+func (er *ErrResponse) Is(target error) bool {
+	if er == nil {
+		return false
+	}
+	ec, ok := target.(*errClass)
+	return ok && ec.match(er)
+}
+
+func (er *ErrResponse) tokenExpired() bool {
+	return er.Code == CodeAccessToken && er.ErrorSubcode == SubcodeExpired
+}
+
+// tokenInvalid includes expired tokens and tokens that were invalidated by the user.
+func (er *ErrResponse) tokenInvalid() bool {
+	return er.Code == CodeAccessToken || er.Code == CodeSession
+}
+
+func (er *ErrResponse) permissionDenied() bool {
+	return er.Code == CodePermissionDenied || (er.Code >= CodePermissionMin && er.Code <= CodePermissionMax)
+}
+
+func (er *ErrResponse) rateLimited() bool {
+	switch er.Code {
+	case CodeTooManyCalls, CodeUserTooManyCalls, CodePageTooManyCalls, CodeAppLimitReached, CodeCustomRateLimited:
+		return true
+	}
+	return er.Code >= CodeBusinessUseCaseMin && er.Code <= CodeBusinessUseCaseMax
+}
+
+func (er *ErrResponse) transient() bool {
+	return er.Transient || er.Code == CodeUnknown || er.Code == CodeService
+}
+
+func (er *ErrResponse) userActionRequired() bool {
+	if er.Code != CodeAccessToken && er.Code != CodeSession {
+		return false
+	}
+	switch er.ErrorSubcode {
+	case SubcodeUserCheckpointed, SubcodePasswordChanged, SubcodeUnconfirmedUser, SubcodeCheckpointedBlocked:
+		return true
+	}
+	return false
+}
+
+func (er *ErrResponse) duplicatePost() bool {
+	return er.Code == CodeDuplicatePost
+}
+
+// IsTokenExpired says if the error is an *ErrResponse saying that the access token has expired.
+func IsTokenExpired(err error) bool {
+	return errors.Is(err, ErrTokenExpired)
+}
+
+// IsTokenInvalid says if the error is an *ErrResponse saying that the access token is invalid for any reason,
+// including expiration. A new token must be obtained.
+func IsTokenInvalid(err error) bool {
+	return errors.Is(err, ErrTokenInvalid)
+}
+
+// IsPermissionDenied says if the error is an *ErrResponse saying that a permission is missing.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied)
+}
+
+// IsRateLimited says if the error is an *ErrResponse saying that the app, user, page, or business use case has made
+// too many calls.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsTransient says if the error is an *ErrResponse for a temporary issue, so the request may be retried as is.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrTransient)
+}
+
+// IsUserActionRequired says if the error is an *ErrResponse saying that the user must log in to Facebook to resolve
+// an issue with the account before the app can make calls for the user again.
+func IsUserActionRequired(err error) bool {
+	return errors.Is(err, ErrUserActionRequired)
+}
+
+// IsDuplicatePost says if the error is an *ErrResponse saying that the same message was posted twice in a row.
+func IsDuplicatePost(err error) bool {
+	return errors.Is(err, ErrDuplicatePost)
+}
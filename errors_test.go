@@ -0,0 +1,65 @@
+package fb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrResponse_Is(t *testing.T) {
+	cases := []struct {
+		Err      *ErrResponse
+		Expected []error // All other sentinels must not match.
+	}{
+		{&ErrResponse{Code: 190, ErrorSubcode: 463}, []error{ErrTokenExpired, ErrTokenInvalid}},
+		{&ErrResponse{Code: 190, ErrorSubcode: 460}, []error{ErrTokenInvalid, ErrUserActionRequired}},
+		{&ErrResponse{Code: 102}, []error{ErrTokenInvalid}},
+		{&ErrResponse{Code: 10}, []error{ErrPermissionDenied}},
+		{&ErrResponse{Code: 230}, []error{ErrPermissionDenied}},
+		{&ErrResponse{Code: 4}, []error{ErrRateLimited}},
+		{&ErrResponse{Code: 17}, []error{ErrRateLimited}},
+		{&ErrResponse{Code: 32}, []error{ErrRateLimited}},
+		{&ErrResponse{Code: 613}, []error{ErrRateLimited}},
+		{&ErrResponse{Code: 80004}, []error{ErrRateLimited}},
+		{&ErrResponse{Code: 1}, []error{ErrTransient}},
+		{&ErrResponse{Code: 2}, []error{ErrTransient}},
+		{&ErrResponse{Code: 100, Transient: true}, []error{ErrTransient}},
+		{&ErrResponse{Code: 506}, []error{ErrDuplicatePost}},
+		{&ErrResponse{Code: 100}, nil},
+	}
+	all := []error{ErrTokenExpired, ErrTokenInvalid, ErrPermissionDenied, ErrRateLimited, ErrTransient,
+		ErrUserActionRequired, ErrDuplicatePost}
+	for i, tc := range cases {
+		wrapped := fmt.Errorf("wrapped: %w", tc.Err)
+		for _, sentinel := range all {
+			expected := false
+			for _, e := range tc.Expected {
+				if e == sentinel {
+					expected = true
+				}
+			}
+			if got := errors.Is(wrapped, sentinel); got != expected {
+				t.Errorf("case %d: errors.Is(%v, %v) = %v", i, tc.Err, sentinel, got)
+			}
+		}
+	}
+}
+
+func TestIsErrResponse(t *testing.T) {
+	if !IsErrResponse(&ErrResponse{}) {
+		t.Error("expected an *ErrResponse to be detected")
+	}
+	if !IsErrResponse(fmt.Errorf("wrapped: %w", &ErrResponse{})) {
+		t.Error("expected a wrapped *ErrResponse to be detected")
+	}
+	if IsErrResponse(errors.New("other")) {
+		t.Error("expected a different error type not to be detected")
+	}
+	var nilErr *ErrResponse
+	if errors.Is(nilErr, ErrTokenInvalid) || IsTransient(nilErr) {
+		t.Error("expected a nil *ErrResponse to match no category")
+	}
+	if !IsRateLimited(&ErrResponse{Code: 4}) || IsTokenExpired(&ErrResponse{Code: 4}) {
+		t.Error("the helper functions do not classify correctly")
+	}
+}
@@ -1,17 +1,20 @@
 // Package fb provides helpers for using the Facebook Graph API.
+//
+// The functions named with a Req suffix set up an *http.Request without running it. To make such a request
+// cancelable or give it a deadline, run it with Client.DoContext or attach the context with Request.WithContext.
+// These functions use DefaultClient; to send such a request with another Client, adapt it with Client.Rebase.
 package fb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
-	"time"
 )
 
 type GraphResponseMe struct {
@@ -35,16 +38,26 @@ type GraphResponsePage struct {
 // A WebhookNotif represents any webhook notification payload.
 // Info https://developers.facebook.com/docs/graph-api/webhooks
 type WebhookNotif struct {
-	Object string `json:"object"` // enum{user, page, permissions, payments}
-	Entry  []struct {
-		ID            string   `json:"id"`
-		ChangedFields []string `json:"changed_fields"` // Fields include, e.g., for Page "leadgen", "location", "messages", etc.
-		Changes       []struct {
-			Field string          `json:"field"`
-			Value json.RawMessage `json:"value"` // Not set for some endpoints.
-		} `json:"changes"`
-		Time int `json:"time"` // A Unix timestamp.
-	} `json:"entry"`
+	Object string         `json:"object"` // enum{user, page, permissions, payments, instagram, application}
+	Entry  []WebhookEntry `json:"entry"`
+}
+
+// A WebhookEntry is one entry of a webhook notification, which concerns the object with the given ID.
+type WebhookEntry struct {
+	ID            string          `json:"id"`
+	ChangedFields []string        `json:"changed_fields"` // Fields include, e.g., for Page "leadgen", "location", "messages", etc.
+	Changes       []WebhookChange `json:"changes"`
+	Time          int64           `json:"time"` // A Unix timestamp (in milliseconds for messaging entries).
+
+	// Messenger platform events, given instead of Changes for the "messages", "messaging_*", and "message_*" fields.
+	Messaging []MessagingEvent `json:"messaging"`
+	Standby   []MessagingEvent `json:"standby"` // Events for threads that the app does not control.
+}
+
+// A WebhookChange gives the new value of a field that changed.
+type WebhookChange struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"` // Not set for some endpoints.
 }
 
 // A LeadGenEntry is a Page webhook notification value for the field "leadgen".
@@ -78,11 +91,34 @@ func (le *LeadGenEntry) MarshalJSON() ([]byte, error) {
 
 // NextPage makes a request to nextURL using the given client. If Client is nil, then http.DefaultClient is used.
 func NextPage(nextURL string, client *http.Client) (*http.Response, error) {
+	return NextPageContext(context.Background(), nextURL, client)
+}
+
+// NextPageContext is like NextPage but sends the request with the given context. If DefaultClient has an AppSecret,
+// the appsecret_proof parameter is added to the URL if it is missing.
+func NextPageContext(ctx context.Context, nextURL string, client *http.Client) (*http.Response, error) {
+	req, err := nextPageReq(nextURL, DefaultClient.AppSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req.WithContext(ctx))
+}
+
+// nextPageReq sets up a GET request to nextURL, adding the appsecret_proof parameter if appSecret is not empty.
+func nextPageReq(nextURL, appSecret string) (*http.Request, error) {
 	u, err := url.Parse(nextURL)
 	if err != nil {
 		return nil, err
 	}
-	req := &http.Request{
+	if appSecret != "" {
+		q := u.Query()
+		setAppsecretProof(q, appSecret)
+		u.RawQuery = q.Encode()
+	}
+	return &http.Request{
 		Method:     http.MethodGet,
 		URL:        u,
 		Proto:      "HTTP/1.1",
@@ -91,11 +127,7 @@ func NextPage(nextURL string, client *http.Client) (*http.Response, error) {
 		Header:     make(http.Header),
 		Body:       nil,
 		Host:       u.Host,
-	}
-	if client == nil {
-		client = http.DefaultClient
-	}
-	return client.Do(req)
+	}, nil
 }
 
 type CursorPaging struct {
@@ -131,6 +163,13 @@ type ErrResponse struct {
 	ErrorUserTitle   string `json:"error_user_title"`
 	ErrorUserMessage string `json:"error_user_message"`
 	FbTraceID        string `json:"fbtrace_id"`
+	Transient        bool   `json:"is_transient"`
+
+	// The following fields are set by DecodeResponse from the HTTP response.
+	HTTPStatus int    `json:"-"` // The HTTP status code of the response.
+	TraceID    string `json:"-"` // The x-fb-trace-id header, useful when reporting bugs to Facebook.
+	Debug      string `json:"-"` // The x-fb-debug header, useful when reporting bugs to Facebook.
+	Usage      *Usage `json:"-"` // The rate limit usage headers; nil if none were given.
 }
 
 // Error gives the main details of the error code and message.
@@ -152,52 +191,33 @@ func (er *ErrResponse) UserErrMessage() string {
 }
 
 // IsErrResponse says if the error is of type *ErrResponse, which Facebook can send as part of a response payload.
+// The error may also wrap an *ErrResponse.
 func IsErrResponse(err error) bool {
-	_, v := err.(*ErrResponse)
-	return v
+	var er *ErrResponse
+	return errors.As(err, &er)
 }
 
 // Req sets up a request to the Facebook API but does not run it. The method should one of GET, POST, or DELETE.
-// The nodeEdge parameter should not have a leading slash or the Graph API version (currently set to 2.12).
-// Leave the fields slice empty or nil to not specify a fields parameter.
+// The nodeEdge parameter should not have a leading slash or the Graph API version (DefaultVersion is used).
+// Leave the fields slice empty or nil to not specify a fields parameter. Req uses DefaultClient.
 func Req(method, nodeEdge string, accessToken string, fields []string, params ...Param) *http.Request {
-	r := &http.Request{
-		Method: method,
-		URL: &url.URL{
-			Scheme: "https",
-			Host:   "graph.facebook.com",
-			Path:   "/v2.12/" + nodeEdge,
-		},
-		Proto:      "HTTP/1.1",
-		ProtoMajor: 1,
-		ProtoMinor: 1,
-		Header:     make(http.Header),
-	}
-	if len(fields) > 0 {
-		params = append(params, &ParamStrStr{
-			K: "fields",
-			V: strings.Join(fields, ","),
-		})
-	}
-	if method == http.MethodPost {
-		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		b := new(bytes.Buffer)
-		b.WriteString(encodeParams(accessToken, params))
-		r.ContentLength = int64(b.Len())
-		buf := b.Bytes()
-		r.Body = ioutil.NopCloser(b)
-		r.GetBody = func() (io.ReadCloser, error) {
-			return ioutil.NopCloser(bytes.NewReader(buf)), nil
-		}
-	} else {
-		r.URL.RawQuery = encodeParams(accessToken, params)
-	}
-	return r
+	return DefaultClient.Req(method, nodeEdge, accessToken, fields, params...)
 }
 
-// ReqDo uses Req to set up the request and then runs Do on it. The client request timeout is set to 12 seconds.
+// ReqDo uses Req to set up the request and then runs Do on it with DefaultClient. Unless DefaultClient is given an
+// HTTPClient, the client request timeout is 12 seconds.
 func ReqDo(method, nodeEdge string, accessToken string, fields []string, params ...Param) (*http.Response, error) {
-	return (&http.Client{Timeout: time.Second * 12}).Do(Req(method, nodeEdge, accessToken, fields, params...))
+	return DefaultClient.ReqDo(method, nodeEdge, accessToken, fields, params...)
+}
+
+// ReqContext is like Req but sets up the request with the given context.
+func ReqContext(ctx context.Context, method, nodeEdge string, accessToken string, fields []string, params ...Param) *http.Request {
+	return DefaultClient.ReqContext(ctx, method, nodeEdge, accessToken, fields, params...)
+}
+
+// ReqDoContext is like ReqDo but runs the request with the given context.
+func ReqDoContext(ctx context.Context, method, nodeEdge string, accessToken string, fields []string, params ...Param) (*http.Response, error) {
+	return DefaultClient.ReqDoContext(ctx, method, nodeEdge, accessToken, fields, params...)
 }
 
 // A Param is a key => value pair to be sent in the request.
@@ -226,18 +246,33 @@ func (psi *ParamStrInt) Key() string { return psi.K }
 func (psi *ParamStrInt) Val() string { return strconv.FormatInt(psi.V, 10) }
 
 // encodeParams builds url.Values from the given Param elements. This function sets the access token parameter
-// if it is not empty.
-func encodeParams(accessToken string, params []Param) string {
-	v := make(url.Values, len(params)+1)
+// if it is not empty and, if appSecret is also not empty, the appsecret_proof parameter computed for the token
+// unless the params already include one.
+func encodeParams(accessToken, appSecret string, params []Param) string {
+	v := make(url.Values, len(params)+2)
 	if accessToken != "" {
 		v.Set("access_token", accessToken)
 	}
 	for _, p := range params {
 		v.Set(p.Key(), p.Val())
 	}
+	setAppsecretProof(v, appSecret)
 	return v.Encode()
 }
 
+// setAppsecretProof sets the appsecret_proof value for the access_token in v if appSecret is not empty and v has an
+// access_token but no appsecret_proof yet.
+func setAppsecretProof(v url.Values, appSecret string) {
+	accessToken := v.Get("access_token")
+	if appSecret == "" || accessToken == "" || v.Get("appsecret_proof") != "" {
+		return
+	}
+	proof, err := AppsecretProof(accessToken, appSecret)
+	if err == nil {
+		v.Set("appsecret_proof", proof)
+	}
+}
+
 // ReadResponse simply reads the response and decodes it into v, which should be a non-nil pointer to a variable that
 // can take an error response (in the Facebook Graph way) or the actual response expected. This function closes the
 // http.Response body upon returning.
@@ -246,3 +281,42 @@ func ReadResponse(res *http.Response, v interface{}) error {
 	res.Body.Close()
 	return err
 }
+
+// DecodeResponse reads the response like ReadResponse but also detects whether Facebook replied with an error. If the
+// payload contains an "error" object or the response status code is not 2xx, an *ErrResponse is returned with its
+// HTTPStatus, TraceID, Debug, and Usage fields set; v is still given whatever could be decoded. The v parameter may
+// be nil if only the error is of interest. This function closes the http.Response body upon returning.
+func DecodeResponse(res *http.Response, v interface{}) error {
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	return decodeBody(res.StatusCode, res.Header, body, v)
+}
+
+// decodeBody decodes a response body given with the status code and headers as described for DecodeResponse.
+func decodeBody(status int, header http.Header, body []byte, v interface{}) error {
+	var envelope struct {
+		Error *ErrResponse `json:"error"`
+	}
+	json.Unmarshal(body, &envelope) // The body may not be a JSON object at all.
+	er := envelope.Error
+	if er == nil && (status < 200 || status > 299) {
+		er = &ErrResponse{Message: "unexpected HTTP status " + strconv.Itoa(status) + " " + http.StatusText(status)}
+	}
+	if er != nil {
+		er.HTTPStatus = status
+		er.TraceID = header.Get("x-fb-trace-id")
+		er.Debug = header.Get("x-fb-debug")
+		er.Usage, _ = ParseUsage(header)
+		if v != nil {
+			json.Unmarshal(body, v)
+		}
+		return er
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
@@ -1,6 +1,11 @@
 package fb
 
-import "testing"
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
 
 func TestLeadGenEntry_MarshalJSON(t *testing.T) {
 	leads := []struct {
@@ -35,3 +40,46 @@ func TestLeadGenEntry_MarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeResponse(t *testing.T) {
+	cases := []struct {
+		Status  int
+		Body    string
+		Code    int64 // zero if no error is expected
+		Success bool
+	}{
+		{200, `{"success":true}`, 0, true},
+		{200, `{"error":{"message":"Invalid OAuth access token.","type":"OAuthException","code":190}}`, 190, false},
+		{400, `{"error":{"message":"(#100) Invalid parameter","type":"OAuthException","code":100}}`, 100, false},
+		{502, `<html>Bad Gateway</html>`, 0, false},
+	}
+	for i, tc := range cases {
+		h := make(http.Header)
+		h.Set("x-fb-trace-id", "trace")
+		h.Set("x-fb-debug", "debug")
+		resp := &http.Response{
+			StatusCode: tc.Status,
+			Header:     h,
+			Body:       ioutil.NopCloser(strings.NewReader(tc.Body)),
+		}
+		v := new(SubscribeAppResponse)
+		err := DecodeResponse(resp, v)
+		if tc.Status == 200 && tc.Code == 0 {
+			if err != nil {
+				t.Errorf("case %d: got error %v", i, err)
+			}
+		} else {
+			er, ok := err.(*ErrResponse)
+			if !ok {
+				t.Errorf("case %d: expected an *ErrResponse but got %v", i, err)
+				continue
+			}
+			if er.Code != tc.Code || er.HTTPStatus != tc.Status || er.TraceID != "trace" || er.Debug != "debug" {
+				t.Errorf("case %d: got unexpected error %+v", i, *er)
+			}
+		}
+		if v.Success != tc.Success {
+			t.Errorf("case %d: got Success %v", i, v.Success)
+		}
+	}
+}
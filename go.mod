@@ -0,0 +1,3 @@
+module github.com/dchenk/go-graph-fb
+
+go 1.18
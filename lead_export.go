@@ -0,0 +1,128 @@
+package fb
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// A LeadExporter writes out all the leads of lead forms, paging through the leads of each form so that the leads do
+// not all have to be held in memory.
+type LeadExporter struct {
+	Client      *Client // If nil, DefaultClient is used.
+	AccessToken string  // A page access token of the page the forms belong to.
+
+	// Columns are the field names to use as CSV columns after the "form_id", "id", and "created_time" columns. If
+	// empty, the keys of the questions of the forms are used, as given by LeadgenForm.FieldKeys.
+	Columns []string
+}
+
+func (le *LeadExporter) client() *Client {
+	if le.Client != nil {
+		return le.Client
+	}
+	return DefaultClient
+}
+
+// each calls f for each lead of each form.
+func (le *LeadExporter) each(ctx context.Context, formIDs []string, f func(formID string, lead *FormLead) error) error {
+	c := le.client()
+	for _, formID := range formIDs {
+		it := NewCursorIter[FormLead](ctx, c, FormLeadsReq(le.AccessToken, formID))
+		for it.Next() {
+			lead := it.Item()
+			if err := f(formID, &lead); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the leads of the forms as CSV with a header row. Fields with multiple values have the values
+// joined with commas.
+func (le *LeadExporter) WriteCSV(ctx context.Context, w io.Writer, formIDs ...string) error {
+	columns := le.Columns
+	if len(columns) == 0 {
+		var err error
+		if columns, err = le.formColumns(ctx, formIDs); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"form_id", "id", "created_time"}, columns...)); err != nil {
+		return err
+	}
+	row := make([]string, 3+len(columns))
+	err := le.each(ctx, formIDs, func(formID string, lead *FormLead) error {
+		row[0], row[1], row[2] = formID, lead.ID, lead.CreatedTime
+		for i, name := range columns {
+			row[3+i] = strings.Join(lead.GetAll(name), ",")
+		}
+		return cw.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formColumns gives the keys of the questions of the forms, without duplicates, in the order of the forms.
+func (le *LeadExporter) formColumns(ctx context.Context, formIDs []string) ([]string, error) {
+	c := le.client()
+	var columns []string
+	seen := make(map[string]bool)
+	for _, formID := range formIDs {
+		resp, err := c.DoContext(ctx, c.Rebase(LeadgenFormReq(le.AccessToken, formID)))
+		if err != nil {
+			return nil, err
+		}
+		form := new(LeadgenForm)
+		if err := c.DecodeResponse(resp, form); err != nil {
+			return nil, err
+		}
+		for _, key := range form.FieldKeys() {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// WriteJSONL writes the leads of the forms as JSON Lines, one lead encoded with FormLead.MarshalJSON per line. The
+// form_id of each line tells which form the lead was submitted with.
+func (le *LeadExporter) WriteJSONL(ctx context.Context, w io.Writer, formIDs ...string) error {
+	bw := bufio.NewWriter(w)
+	err := le.each(ctx, formIDs, func(formID string, lead *FormLead) error {
+		if lead.FormID == "" {
+			lead.FormID = formID
+		}
+		b, _ := lead.MarshalJSON()
+		bw.Write(b)
+		return bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// PageFormIDs lists the IDs of all the lead forms of the page, paging through the leadgen_forms edge that
+// PageLeadgenSetupReq gives the first page of.
+func (le *LeadExporter) PageFormIDs(ctx context.Context, pageID string) ([]string, error) {
+	it := NewCursorIter[PageLeadgenForm](ctx, le.client(), PageLeadgenFormsReq(le.AccessToken, pageID))
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+	return ids, it.Err()
+}
@@ -0,0 +1,61 @@
+package fb
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLeadExporter(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/page1/leadgen_forms":
+			w.Write([]byte(`{"data":[{"id":"form1"},{"id":"form2"}]}`))
+		case "/" + DefaultVersion + "/form1":
+			w.Write([]byte(`{"id":"form1","questions":[{"key":"email","type":"EMAIL"}]}`))
+		case "/" + DefaultVersion + "/form2":
+			w.Write([]byte(`{"id":"form2","questions":[{"type":"EMAIL"},{"key":"topics","type":"CUSTOM"}]}`))
+		case "/" + DefaultVersion + "/form1/leads":
+			w.Write([]byte(`{"data":[{"id":"1","created_time":"2018-04-04T17:56:02+0000",` +
+				`"field_data":[{"name":"email","values":["a@example.com"]}]}]}`))
+		case "/" + DefaultVersion + "/form2/leads":
+			w.Write([]byte(`{"data":[{"id":"2","form_id":"form2","created_time":"2018-04-05T17:56:02+0000",` +
+				`"field_data":[{"name":"email","values":["b@example.com"]},{"name":"topics","values":["x","y"]}]}]}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	le := &LeadExporter{Client: c, AccessToken: "abc"}
+	ctx := context.Background()
+	formIDs, err := le.PageFormIDs(ctx, "page1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(formIDs) != 2 {
+		t.Fatalf("got form IDs %v", formIDs)
+	}
+
+	var b bytes.Buffer
+	if err := le.WriteCSV(ctx, &b, formIDs...); err != nil {
+		t.Fatal(err)
+	}
+	expected := "form_id,id,created_time,email,topics\n" +
+		"form1,1,2018-04-04T17:56:02+0000,a@example.com,\n" +
+		"form2,2,2018-04-05T17:56:02+0000,b@example.com,\"x,y\"\n"
+	if b.String() != expected {
+		t.Errorf("got CSV:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := le.WriteJSONL(ctx, &b, formIDs...); err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"created_time":"2018-04-04T17:56:02+0000","id":"1","form_id":"form1","field_data":[{"name":"email","values":["a@example.com"]}]}` +
+		"\n" + `{"created_time":"2018-04-05T17:56:02+0000","id":"2","form_id":"form2","field_data":[{"name":"email","values":["b@example.com"]},` +
+		`{"name":"topics","values":["x","y"]}]}` + "\n"
+	if b.String() != expected {
+		t.Errorf("got JSON Lines:\n%s", b.String())
+	}
+}
@@ -0,0 +1,60 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testCheckpoints map[string]time.Time
+
+func (tc testCheckpoints) Checkpoint(ctx context.Context, formID string) (time.Time, error) {
+	return tc[formID], nil
+}
+
+func (tc testCheckpoints) SetCheckpoint(ctx context.Context, formID string, t time.Time) error {
+	tc[formID] = t
+	return nil
+}
+
+func TestLeadSync_Sync(t *testing.T) {
+	var filtering []map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		filtering = nil
+		if f := r.URL.Query().Get("filtering"); f != "" {
+			if err := json.Unmarshal([]byte(f), &filtering); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		w.Write([]byte(`{"data":[{"id":"2","created_time":"2018-04-05T10:00:00+0000"},` +
+			`{"id":"1","created_time":"2018-04-04T10:00:00+0000"}]}`))
+	})
+
+	store := make(testCheckpoints)
+	ls := &LeadSync{Client: c, AccessToken: "abc", Store: store}
+	ctx := context.Background()
+	handle := func(ctx context.Context, lead *FormLead) error { return nil }
+
+	n, err := ls.Sync(ctx, "form1", handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || filtering != nil {
+		t.Errorf("got %d leads with filtering %v on the first sync", n, filtering)
+	}
+	newest := time.Date(2018, 4, 5, 10, 0, 0, 0, time.UTC)
+	if !store["form1"].Equal(newest) {
+		t.Errorf("got checkpoint %v", store["form1"])
+	}
+
+	if _, err := ls.Sync(ctx, "form1", handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(filtering) != 1 || filtering[0]["field"] != "time_created" || filtering[0]["operator"] != "GREATER_THAN" ||
+		filtering[0]["value"] != float64(newest.Unix()-1) {
+		t.Errorf("got filtering %v on the second sync", filtering)
+	}
+}
@@ -0,0 +1,162 @@
+package fb
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListLeadgenAccessUsersReq returns a request to list the users who have been given access to the leads of the page
+// with the Leads Access Manager. If the list is empty, all admins of the page have access. Use the
+// LeadgenAccessUserList type for responses.
+func ListLeadgenAccessUsersReq(pageAccessToken, pageID string) *http.Request {
+	return Req(http.MethodGet, pageID+"/leadgen_whitelisted_users", pageAccessToken, nil)
+}
+
+// A LeadgenAccessUserList lists the users who have access to the leads of a page.
+type LeadgenAccessUserList struct {
+	Data []struct {
+		UserID   string `json:"user_id"`
+		UserName string `json:"user_name"`
+	} `json:"data"`
+	Paging CursorPaging `json:"paging"`
+	Error  *ErrResponse `json:"error"` // nil if no error is given
+}
+
+// AddLeadgenAccessUserReq returns a request to give the user access to the leads of the page. Use the
+// SubscribeAppResponse type for responses.
+func AddLeadgenAccessUserReq(pageAccessToken, pageID, userID string) *http.Request {
+	return Req(http.MethodPost, pageID+"/leadgen_whitelisted_users", pageAccessToken, nil, &ParamStrStr{"user_id", userID})
+}
+
+// RemoveLeadgenAccessUserReq returns a request to remove the access of the user to the leads of the page. Use the
+// SubscribeAppResponse type for responses.
+func RemoveLeadgenAccessUserReq(pageAccessToken, pageID, userID string) *http.Request {
+	return Req(http.MethodDelete, pageID+"/leadgen_whitelisted_users", pageAccessToken, nil, &ParamStrStr{"user_id", userID})
+}
+
+// AssignCRMReq returns a request to assign the app whose page access token is used as a CRM of the page: the app is
+// subscribed to the "leadgen" field of the page, after which it appears as a CRM in the Leads Access Manager and
+// receives leadgen webhook notifications. Use the SubscribeAppResponse type for responses.
+func AssignCRMReq(pageAccessToken, pageID string) *http.Request {
+	return Req(http.MethodPost, pageID+"/subscribed_apps", pageAccessToken, nil, &ParamStrStr{"subscribed_fields", "leadgen"})
+}
+
+// A LeadAccessProblem is a reason why the leads of a page cannot be retrieved.
+type LeadAccessProblem int
+
+// The problems found by DiagnoseLeadAccess.
+const (
+	LeadAccessTokenInvalid      LeadAccessProblem = iota + 1 // The user access token is not valid.
+	LeadAccessMissingPermission                              // The user did not grant the leads_retrieval permission.
+	LeadAccessNotPageAdmin                                   // The user does not have a role on the page.
+	LeadAccessAppNotSubscribed                               // The app is not subscribed to the page.
+	LeadAccessUserNotAllowed                                 // The Leads Access Manager does not list the user.
+)
+
+// String explains the problem.
+func (p LeadAccessProblem) String() string {
+	switch p {
+	case LeadAccessTokenInvalid:
+		return "the user access token is not valid"
+	case LeadAccessMissingPermission:
+		return "the user has not granted the leads_retrieval permission"
+	case LeadAccessNotPageAdmin:
+		return "the user does not have a role on the page"
+	case LeadAccessAppNotSubscribed:
+		return "the app is not subscribed to the page"
+	case LeadAccessUserNotAllowed:
+		return "the user has not been given lead access in the Leads Access Manager"
+	}
+	return "unknown problem"
+}
+
+// A LeadAccessDiagnosis gives the problems found by DiagnoseLeadAccess.
+type LeadAccessDiagnosis struct {
+	Problems        []LeadAccessProblem
+	PageAccessToken string // The page access token derived from the user token; empty if the page was not found.
+}
+
+// OK says if no problem was found.
+func (d *LeadAccessDiagnosis) OK() bool {
+	return len(d.Problems) == 0
+}
+
+// DiagnoseLeadAccess explains why the leads of the page may not be retrievable with the user access token by the app
+// with the given ID. It checks the validity and permissions of the token, the role of the user on the page, the
+// subscription of the app to the page, and the users listed by the Leads Access Manager. The error returned is for
+// a failure to make the checks, not for the problems found.
+func (c *Client) DiagnoseLeadAccess(ctx context.Context, userAccessToken, pageID, appID string) (*LeadAccessDiagnosis, error) {
+	d := new(LeadAccessDiagnosis)
+	debug, err := c.DebugTokenContext(ctx, userAccessToken, userAccessToken)
+	if err != nil && !IsTokenInvalid(err) {
+		return nil, err
+	}
+	if err == nil && debug.Error != nil {
+		if !IsTokenInvalid(debug.Error) {
+			// Rate limits and transient errors say nothing about the user token.
+			return nil, debug.Error
+		}
+		err = debug.Error
+	}
+	if err != nil || !debug.Data.IsValid {
+		d.Problems = append(d.Problems, LeadAccessTokenInvalid)
+		return d, nil
+	}
+	if !containsString(debug.Data.Scopes, "leads_retrieval") {
+		d.Problems = append(d.Problems, LeadAccessMissingPermission)
+	}
+
+	pages := NewCursorIter[UserPage](ctx, c, ListUserPagesFieldsReq(userAccessToken, []string{"id", "access_token"}))
+	for pages.Next() {
+		if pages.Item().ID == pageID {
+			d.PageAccessToken = pages.Item().AccessToken
+			break
+		}
+	}
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
+	if d.PageAccessToken == "" {
+		d.Problems = append(d.Problems, LeadAccessNotPageAdmin)
+		return d, nil
+	}
+
+	apps := NewCursorIter[struct {
+		ID string `json:"id"`
+	}](ctx, c, ListPageSubscribedAppsReq(d.PageAccessToken, pageID))
+	subscribed := false
+	for !subscribed && apps.Next() {
+		subscribed = apps.Item().ID == appID
+	}
+	if err := apps.Err(); err != nil {
+		return nil, err
+	}
+	if !subscribed {
+		d.Problems = append(d.Problems, LeadAccessAppNotSubscribed)
+	}
+
+	users := NewCursorIter[struct {
+		UserID string `json:"user_id"`
+	}](ctx, c, ListLeadgenAccessUsersReq(d.PageAccessToken, pageID))
+	listed, allowed := false, false
+	for users.Next() {
+		listed = true
+		allowed = allowed || users.Item().UserID == debug.Data.UserID
+	}
+	if err := users.Err(); err != nil {
+		return nil, err
+	}
+	if listed && !allowed {
+		d.Problems = append(d.Problems, LeadAccessUserNotAllowed)
+	}
+	return d, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package fb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_DiagnoseLeadAccess(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/debug_token":
+			w.Write([]byte(`{"data":{"is_valid":true,"user_id":"u1","scopes":["pages_show_list"]}}`))
+		case "/" + DefaultVersion + "/me/accounts":
+			w.Write([]byte(`{"data":[{"id":"page1","access_token":"page-token"}]}`))
+		case "/" + DefaultVersion + "/page1/subscribed_apps":
+			if r.URL.Query().Get("access_token") != "page-token" {
+				t.Error("the page access token was not used")
+			}
+			if r.URL.Query().Get("after") == "" {
+				next := "http://" + r.Host + r.URL.Path + "?access_token=page-token&after=c1"
+				w.Write([]byte(`{"data":[{"id":"other-app"}],"paging":{"cursors":{"after":"c1"},"next":"` + next + `"}}`))
+				return
+			}
+			w.Write([]byte(`{"data":[{"id":"app2"}]}`))
+		case "/" + DefaultVersion + "/page1/leadgen_whitelisted_users":
+			w.Write([]byte(`{"data":[{"user_id":"u2","user_name":"Other"}]}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	d, err := c.DiagnoseLeadAccess(context.Background(), "user-token", "page1", "app1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LeadAccessProblem{LeadAccessMissingPermission, LeadAccessAppNotSubscribed, LeadAccessUserNotAllowed}
+	if len(d.Problems) != len(expected) {
+		t.Fatalf("got problems %v", d.Problems)
+	}
+	for i := range expected {
+		if d.Problems[i] != expected[i] {
+			t.Errorf("got problem %v; expected %v", d.Problems[i], expected[i])
+		}
+	}
+	if d.OK() || d.PageAccessToken != "page-token" {
+		t.Errorf("got diagnosis %+v", *d)
+	}
+
+	// The app is found on a later page of the subscribed apps.
+	d, err = c.DiagnoseLeadAccess(context.Background(), "user-token", "page1", "app2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range d.Problems {
+		if p == LeadAccessAppNotSubscribed {
+			t.Errorf("got problems %v for an app on the second page of subscribed apps", d.Problems)
+		}
+	}
+
+	d, err = c.DiagnoseLeadAccess(context.Background(), "user-token", "page2", "app1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Problems) != 2 || d.Problems[1] != LeadAccessNotPageAdmin {
+		t.Errorf("got problems %v for a page the user has no role on", d.Problems)
+	}
+}
+
+func TestClient_DiagnoseLeadAccess_DebugError(t *testing.T) {
+	var body string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+DefaultVersion+"/debug_token" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
+	})
+
+	body = `{"error":{"message":"Application request limit reached","code":4}}`
+	if _, err := c.DiagnoseLeadAccess(context.Background(), "user-token", "page1", "app1"); !IsRateLimited(err) {
+		t.Errorf("got error %v for a rate limit", err)
+	}
+
+	body = `{"error":{"message":"Error validating access token","code":190}}`
+	d, err := c.DiagnoseLeadAccess(context.Background(), "user-token", "page1", "app1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Problems) != 1 || d.Problems[0] != LeadAccessTokenInvalid {
+		t.Errorf("got problems %v for an invalid token", d.Problems)
+	}
+}
@@ -0,0 +1,288 @@
+package fb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// LeadgenFormReq returns a request to read the full definition of a lead form. Use the LeadgenForm type for responses.
+// Fields retrieved: id,name,status,locale,questions,privacy_policy_url,legal_content{...},context_card{...},
+// thank_you_page{...},follow_up_action_url,leads_count,expired_leads_count,created_time
+func LeadgenFormReq(pageAccessToken, formID string) *http.Request {
+	return Req(http.MethodGet, formID, pageAccessToken, leadgenFormFields)
+}
+
+var leadgenFormFields = []string{"id", "name", "status", "locale", "questions", "privacy_policy_url",
+	"legal_content{id,privacy_policy,custom_disclaimer}",
+	"context_card{id,title,style,content,button_text}",
+	"thank_you_page{id,title,body,button_type,button_text,website_url}",
+	"follow_up_action_url", "leads_count", "expired_leads_count", "created_time"}
+
+// A LeadgenForm is the full definition of a lead form.
+// Info: https://developers.facebook.com/docs/marketing-api/reference/lead-gen-data/
+type LeadgenForm struct {
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	Status            string               `json:"status"` // enum{ACTIVE, ARCHIVED, DELETED, DRAFT}
+	Locale            string               `json:"locale"`
+	Questions         []LeadgenQuestion    `json:"questions"`
+	PrivacyPolicyURL  string               `json:"privacy_policy_url"`
+	LegalContent      *LeadgenLegalContent `json:"legal_content"`
+	ContextCard       *LeadgenContextCard  `json:"context_card"`
+	ThankYouPage      *LeadgenThankYouPage `json:"thank_you_page"`
+	FollowUpActionURL string               `json:"follow_up_action_url"`
+	LeadsCount        int64                `json:"leads_count"`
+	ExpiredLeadsCount int64                `json:"expired_leads_count"`
+	CreatedTime       string               `json:"created_time"`
+	Error             *ErrResponse         `json:"error"` // nil if no error is given
+}
+
+// A LeadgenQuestion is a question of a lead form. The Key is the name given to the answers in FormLead.FieldData.
+type LeadgenQuestion struct {
+	ID      string                  `json:"id,omitempty"`
+	Key     string                  `json:"key,omitempty"`
+	Label   string                  `json:"label,omitempty"`
+	Type    string                  `json:"type"` // enum{CUSTOM, EMAIL, FULL_NAME, PHONE, CITY, ...}
+	Options []LeadgenQuestionOption `json:"options,omitempty"`
+}
+
+// A LeadgenQuestionOption is a choice for a multiple choice question.
+type LeadgenQuestionOption struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// A LeadgenLegalContent gives the privacy policy and the custom disclaimer of a lead form.
+type LeadgenLegalContent struct {
+	ID            string `json:"id"`
+	PrivacyPolicy struct {
+		URL      string `json:"url"`
+		LinkText string `json:"link_text"`
+	} `json:"privacy_policy"`
+	CustomDisclaimer *LeadgenCustomDisclaimer `json:"custom_disclaimer"`
+}
+
+// A LeadgenCustomDisclaimer is a disclaimer shown in a lead form, optionally with consent checkboxes.
+type LeadgenCustomDisclaimer struct {
+	Title string `json:"title"`
+	Body  struct {
+		Text string `json:"text"`
+	} `json:"body"`
+	Checkboxes []struct {
+		Key                string `json:"key"`
+		Text               string `json:"text"`
+		IsRequired         bool   `json:"is_required"`
+		IsCheckedByDefault bool   `json:"is_checked_by_default"`
+	} `json:"checkboxes"`
+}
+
+// A LeadgenContextCard is the intro card shown before the questions of a lead form.
+type LeadgenContextCard struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Style      string   `json:"style"` // enum{LIST_STYLE, PARAGRAPH_STYLE}
+	Content    []string `json:"content"`
+	ButtonText string   `json:"button_text"`
+}
+
+// A LeadgenThankYouPage is the page shown after a lead form is submitted.
+type LeadgenThankYouPage struct {
+	ID         string `json:"id,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Body       string `json:"body,omitempty"`
+	ButtonType string `json:"button_type,omitempty"` // enum{VIEW_WEBSITE, CALL_BUSINESS, DOWNLOAD, ...}
+	ButtonText string `json:"button_text,omitempty"`
+	WebsiteURL string `json:"website_url,omitempty"`
+}
+
+// FieldKeys gives the keys of the questions normalized with NormalizeFieldName, in the order of the questions.
+// These are the names under which the answers are given in FormLead.FieldData, so they can be used to set up the
+// mapping of lead fields to CRM fields.
+func (lf *LeadgenForm) FieldKeys() []string {
+	keys := make([]string, len(lf.Questions))
+	for i := range lf.Questions {
+		key := lf.Questions[i].Key
+		if key == "" {
+			key = lf.Questions[i].Type
+		}
+		keys[i] = NormalizeFieldName(key)
+	}
+	return keys
+}
+
+// Fingerprint gives a hash of the content of the form that a marketer can edit: the name, locale, questions, privacy
+// policy, legal content, context card, thank you page, and follow-up URL. Compare fingerprints over time to detect
+// when a form is edited. The status and lead counts do not affect the fingerprint.
+func (lf *LeadgenForm) Fingerprint() string {
+	content := struct {
+		Name              string               `json:"name"`
+		Locale            string               `json:"locale"`
+		Questions         []LeadgenQuestion    `json:"questions"`
+		PrivacyPolicyURL  string               `json:"privacy_policy_url"`
+		LegalContent      *LeadgenLegalContent `json:"legal_content"`
+		ContextCard       *LeadgenContextCard  `json:"context_card"`
+		ThankYouPage      *LeadgenThankYouPage `json:"thank_you_page"`
+		FollowUpActionURL string               `json:"follow_up_action_url"`
+	}{lf.Name, lf.Locale, lf.Questions, lf.PrivacyPolicyURL, lf.LegalContent, lf.ContextCard, lf.ThankYouPage,
+		lf.FollowUpActionURL}
+	b, _ := json.Marshal(content)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Statuses of a lead form that can be set with UpdateLeadgenFormStatusReq.
+const (
+	LeadgenFormActive   = "ACTIVE"
+	LeadgenFormArchived = "ARCHIVED"
+)
+
+// LeadgenFormParams are the parameters for creating a lead form with CreateLeadgenFormReq.
+// Info: https://developers.facebook.com/docs/marketing-api/guides/lead-ads/create
+type LeadgenFormParams struct {
+	Name              string
+	Questions         []LeadgenQuestion // Leave the ID of each question empty.
+	PrivacyPolicyURL  string
+	PrivacyPolicyText string // The text of the link to the privacy policy; optional.
+	FollowUpActionURL string
+	Locale            string               // Optional, such as "en_US".
+	ContextCardID     string               // Optional; the ID of an existing context card.
+	ThankYouPage      *LeadgenThankYouPage // Optional; leave the ID empty.
+
+	// CustomDisclaimer is an optional disclaimer shown with the privacy policy, including any consent checkboxes.
+	CustomDisclaimer *LeadgenCustomDisclaimer
+
+	// If set, the form is shown only to the people targeted by the ad.
+	BlockDisplayForNonTargetedViewer bool
+}
+
+// params encodes the parameters the way the leadgen_forms edge expects.
+func (p *LeadgenFormParams) params() ([]Param, error) {
+	questions, err := json.Marshal(p.Questions)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := json.Marshal(struct {
+		URL      string `json:"url"`
+		LinkText string `json:"link_text,omitempty"`
+	}{p.PrivacyPolicyURL, p.PrivacyPolicyText})
+	if err != nil {
+		return nil, err
+	}
+	params := []Param{
+		&ParamStrStr{"name", p.Name},
+		&ParamStrStr{"questions", string(questions)},
+		&ParamStrStr{"privacy_policy", string(policy)},
+		&ParamStrStr{"follow_up_action_url", p.FollowUpActionURL},
+	}
+	if p.Locale != "" {
+		params = append(params, &ParamStrStr{"locale", p.Locale})
+	}
+	if p.ContextCardID != "" {
+		params = append(params, &ParamStrStr{"context_card_id", p.ContextCardID})
+	}
+	if p.ThankYouPage != nil {
+		typ, err := json.Marshal(p.ThankYouPage)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, &ParamStrStr{"thank_you_page", string(typ)})
+	}
+	if p.CustomDisclaimer != nil {
+		disclaimer, err := json.Marshal(p.CustomDisclaimer)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, &ParamStrStr{"custom_disclaimer", string(disclaimer)})
+	}
+	if p.BlockDisplayForNonTargetedViewer {
+		params = append(params, &ParamStrStr{"block_display_for_non_targeted_viewer", "true"})
+	}
+	return params, nil
+}
+
+// CreateLeadgenFormReq returns a request to create a lead form on the page. Use the LeadgenFormCreated type for
+// responses.
+func CreateLeadgenFormReq(pageAccessToken, pageID string, p *LeadgenFormParams) (*http.Request, error) {
+	params, err := p.params()
+	if err != nil {
+		return nil, err
+	}
+	return Req(http.MethodPost, pageID+"/leadgen_forms", pageAccessToken, nil, params...), nil
+}
+
+// A LeadgenFormCreated represents the response to a request to create a lead form.
+type LeadgenFormCreated struct {
+	ID    string       `json:"id"`
+	Error *ErrResponse `json:"error"` // nil if no error is given
+}
+
+// UpdateLeadgenFormStatusReq returns a request to set the status of a lead form to LeadgenFormActive or
+// LeadgenFormArchived. Use the SubscribeAppResponse type for responses.
+func UpdateLeadgenFormStatusReq(pageAccessToken, formID, status string) *http.Request {
+	return Req(http.MethodPost, formID, pageAccessToken, nil, &ParamStrStr{"status", status})
+}
+
+// Params gives the parameters to create a copy of the form.
+func (lf *LeadgenForm) Params() *LeadgenFormParams {
+	p := &LeadgenFormParams{
+		Name:              lf.Name,
+		Questions:         make([]LeadgenQuestion, len(lf.Questions)),
+		PrivacyPolicyURL:  lf.PrivacyPolicyURL,
+		FollowUpActionURL: lf.FollowUpActionURL,
+		Locale:            lf.Locale,
+	}
+	for i, q := range lf.Questions {
+		q.ID = ""
+		p.Questions[i] = q
+	}
+	if lf.LegalContent != nil {
+		if p.PrivacyPolicyURL == "" {
+			p.PrivacyPolicyURL = lf.LegalContent.PrivacyPolicy.URL
+		}
+		p.PrivacyPolicyText = lf.LegalContent.PrivacyPolicy.LinkText
+		if lf.LegalContent.CustomDisclaimer != nil {
+			disclaimer := *lf.LegalContent.CustomDisclaimer
+			p.CustomDisclaimer = &disclaimer
+		}
+	}
+	if lf.ContextCard != nil {
+		p.ContextCardID = lf.ContextCard.ID
+	}
+	if lf.ThankYouPage != nil {
+		typ := *lf.ThankYouPage
+		typ.ID = ""
+		p.ThankYouPage = &typ
+	}
+	return p
+}
+
+// DuplicateLeadgenForm reads the definition of the form and creates a copy of it on the page with the given name,
+// returning the ID of the new form.
+func (c *Client) DuplicateLeadgenForm(ctx context.Context, pageAccessToken, pageID, formID, name string) (string, error) {
+	resp, err := c.DoContext(ctx, c.Rebase(LeadgenFormReq(pageAccessToken, formID)))
+	if err != nil {
+		return "", err
+	}
+	form := new(LeadgenForm)
+	if err := c.DecodeResponse(resp, form); err != nil {
+		return "", err
+	}
+	p := form.Params()
+	p.Name = name
+	req, err := CreateLeadgenFormReq(pageAccessToken, pageID, p)
+	if err != nil {
+		return "", err
+	}
+	resp, err = c.DoContext(ctx, c.Rebase(req))
+	if err != nil {
+		return "", err
+	}
+	created := new(LeadgenFormCreated)
+	if err := c.DecodeResponse(resp, created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
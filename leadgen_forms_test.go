@@ -0,0 +1,86 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestLeadgenForm(t *testing.T) {
+	const payload = `{"id":"1","name":"Form","status":"ACTIVE","locale":"en_US","leads_count":12,` +
+		`"questions":[{"key":"email","label":"Email","type":"EMAIL","id":"q1"},` +
+		`{"key":"What's your budget?","label":"Budget","type":"CUSTOM","options":[{"key":"low","value":"Low"}]},` +
+		`{"type":"FULL_NAME"}],` +
+		`"context_card":{"id":"c1","title":"Hi","style":"LIST_STYLE","content":["a","b"],"button_text":"Next"},` +
+		`"thank_you_page":{"id":"t1","title":"Thanks","button_type":"VIEW_WEBSITE","website_url":"https://example.com"}}`
+	form := new(LeadgenForm)
+	if err := json.Unmarshal([]byte(payload), form); err != nil {
+		t.Fatal(err)
+	}
+	keys := form.FieldKeys()
+	if len(keys) != 3 || keys[0] != "email" || keys[1] != "what_s_your_budget" || keys[2] != "full_name" {
+		t.Errorf("got keys %v", keys)
+	}
+	if len(form.Questions[1].Options) != 1 || form.ContextCard.Content[1] != "b" {
+		t.Errorf("got form %+v", *form)
+	}
+
+	fp := form.Fingerprint()
+	form.LeadsCount = 20
+	form.Status = "ARCHIVED"
+	if form.Fingerprint() != fp {
+		t.Error("the fingerprint changed with the lead count or status")
+	}
+	form.Questions[0].Label = "Work email"
+	if form.Fingerprint() == fp {
+		t.Error("the fingerprint did not change when a question was edited")
+	}
+}
+
+func TestClient_DuplicateLeadgenForm(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /" + DefaultVersion + "/form1":
+			w.Write([]byte(`{"id":"form1","name":"Form","locale":"en_US",` +
+				`"questions":[{"id":"q1","key":"email","label":"Email","type":"EMAIL"}],` +
+				`"legal_content":{"privacy_policy":{"url":"https://example.com/privacy","link_text":"Privacy"},` +
+				`"custom_disclaimer":{"title":"Consent","body":{"text":"We may call you."},` +
+				`"checkboxes":[{"key":"c1","text":"I agree","is_required":true,"is_checked_by_default":false}]}},` +
+				`"context_card":{"id":"c1"},"thank_you_page":{"id":"t1","title":"Thanks"},` +
+				`"follow_up_action_url":"https://example.com"}`))
+		case "POST /" + DefaultVersion + "/page1/leadgen_forms":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+				return
+			}
+			expected := map[string]string{
+				"name":                 "Copy",
+				"questions":            `[{"key":"email","label":"Email","type":"EMAIL"}]`,
+				"privacy_policy":       `{"url":"https://example.com/privacy","link_text":"Privacy"}`,
+				"follow_up_action_url": "https://example.com",
+				"locale":               "en_US",
+				"context_card_id":      "c1",
+				"thank_you_page":       `{"title":"Thanks"}`,
+				"custom_disclaimer": `{"title":"Consent","body":{"text":"We may call you."},` +
+					`"checkboxes":[{"key":"c1","text":"I agree","is_required":true,"is_checked_by_default":false}]}`,
+			}
+			for k, v := range expected {
+				if got := r.PostForm.Get(k); got != v {
+					t.Errorf("got %s %q; expected %q", k, got, v)
+				}
+			}
+			w.Write([]byte(`{"id":"form2"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	id, err := c.DuplicateLeadgenForm(context.Background(), "abc", "page1", "form1", "Copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "form2" {
+		t.Errorf("got ID %q", id)
+	}
+}
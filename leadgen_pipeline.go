@@ -0,0 +1,231 @@
+package fb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// A PageTokenSource gives the access token to use for a page.
+type PageTokenSource interface {
+	Token(ctx context.Context, pageID string) (string, error)
+}
+
+// A PageTokenInvalidator is a PageTokenSource that can be told that the token it gave for a page is no longer valid,
+// so that a new one is given the next time.
+type PageTokenInvalidator interface {
+	PageTokenSource
+	Invalidate(pageID string)
+}
+
+// An EnrichedLead combines a leadgen webhook notification value with the lead and the form it was submitted with.
+type EnrichedLead struct {
+	Entry LeadGenEntry
+	Lead  *FormLead
+	Form  *PageLeadgenForm
+}
+
+// A LeadSink receives the leads fetched by a LeadPipeline. It must be safe for concurrent use.
+type LeadSink interface {
+	PutLead(ctx context.Context, lead *EnrichedLead) error
+}
+
+// A DeadLetterSink receives the leadgen entries that a LeadPipeline failed to process, along with the last error.
+// It must be safe for concurrent use.
+type DeadLetterSink interface {
+	PutDeadLetter(ctx context.Context, entry LeadGenEntry, err error) error
+}
+
+// A LeadPipeline fetches the leads that leadgen webhook notifications announce and gives them to a sink. Failures
+// that may be temporary are retried; entries that fail permanently or run out of attempts go to the DeadLetters sink.
+type LeadPipeline struct {
+	Client      *Client         // If nil, DefaultClient is used.
+	Tokens      PageTokenSource // Gives the page access token for the PageID of each entry.
+	Sink        LeadSink
+	DeadLetters DeadLetterSink // If nil, entries that fail are dropped.
+
+	Concurrency int           // The number of entries processed at once by Run; if zero, 4 is used.
+	MaxAttempts int           // The maximum number of attempts per entry; if zero, 3 is used.
+	RetryDelay  time.Duration // The delay before the first retry, doubled for each retry; if zero, 1 second is used.
+
+	formsMu sync.Mutex
+	forms   map[string]*PageLeadgenForm
+}
+
+func (lp *LeadPipeline) client() *Client {
+	if lp.Client != nil {
+		return lp.Client
+	}
+	return DefaultClient
+}
+
+// Run processes the entries received from the channel until it is closed or the context is done. It returns the
+// context error if the context is done, or the first error from the DeadLetters sink.
+func (lp *LeadPipeline) Run(ctx context.Context, entries <-chan LeadGenEntry) error {
+	n := lp.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var runErr error
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case entry, ok := <-entries:
+					if !ok {
+						return
+					}
+					if err := lp.Process(ctx, entry); err != nil {
+						errOnce.Do(func() {
+							runErr = err
+							cancel()
+						})
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if runErr != nil {
+		return runErr
+	}
+	return ctx.Err()
+}
+
+// Process fetches the lead and form for the entry and gives them to the sink, retrying failures that may be temporary.
+// A failure of the sink is retried without fetching the lead again. If the entry fails permanently, it is given to
+// the DeadLetters sink. The error returned is the context error if the context is done, or the error from the
+// DeadLetters sink.
+func (lp *LeadPipeline) Process(ctx context.Context, entry LeadGenEntry) error {
+	var lead *EnrichedLead
+	err := lp.retry(ctx, func() error {
+		var err error
+		lead, err = lp.fetch(ctx, entry)
+		return err
+	}, func(err error) bool {
+		if IsTokenInvalid(err) {
+			if inv, ok := lp.Tokens.(PageTokenInvalidator); ok {
+				inv.Invalidate(entry.PageID)
+				return true
+			}
+		}
+		return retryableLeadErr(err)
+	})
+	if err == nil {
+		err = lp.retry(ctx, func() error {
+			return lp.Sink.PutLead(ctx, lead)
+		}, retryableLeadErr)
+	}
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if lp.DeadLetters == nil {
+		return nil
+	}
+	return lp.DeadLetters.PutDeadLetter(ctx, entry, err)
+}
+
+// retry calls f until it succeeds, it fails with an error for which retryable is false, or the attempts run out,
+// giving the last error. The context error is given if the context is done while waiting to retry.
+func (lp *LeadPipeline) retry(ctx context.Context, f func() error, retryable func(error) bool) error {
+	maxAttempts := lp.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	delay := lp.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+		err = f()
+		if err == nil || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// retryableLeadErr says if processing a lead may succeed if tried again after the error.
+func retryableLeadErr(err error) bool {
+	if errors.Is(err, ErrPageNotFound) {
+		return false
+	}
+	var er *ErrResponse
+	if errors.As(err, &er) {
+		return er.HTTPStatus >= 500 || retryableErrResponse(er)
+	}
+	return true
+}
+
+// fetch fetches the lead and form for the entry.
+func (lp *LeadPipeline) fetch(ctx context.Context, entry LeadGenEntry) (*EnrichedLead, error) {
+	token, err := lp.Tokens.Token(ctx, entry.PageID)
+	if err != nil {
+		return nil, err
+	}
+	c := lp.client()
+	resp, err := c.DoContext(ctx, c.Rebase(FormLeadDataReq(token, entry.LeadgenID)))
+	if err != nil {
+		return nil, err
+	}
+	lead := new(FormLead)
+	if err := c.DecodeResponse(resp, lead); err != nil {
+		return nil, err
+	}
+	form, err := lp.form(ctx, token, entry.FormID)
+	if err != nil {
+		return nil, err
+	}
+	return &EnrichedLead{Entry: entry, Lead: lead, Form: form}, nil
+}
+
+// form gives the basic information about the form, which is cached.
+func (lp *LeadPipeline) form(ctx context.Context, pageAccessToken, formID string) (*PageLeadgenForm, error) {
+	lp.formsMu.Lock()
+	form := lp.forms[formID]
+	lp.formsMu.Unlock()
+	if form != nil {
+		return form, nil
+	}
+	c := lp.client()
+	resp, err := c.DoContext(ctx, c.Rebase(PageLeadgenFormReq(pageAccessToken, formID)))
+	if err != nil {
+		return nil, err
+	}
+	form = new(PageLeadgenForm)
+	if err := c.DecodeResponse(resp, form); err != nil {
+		return nil, err
+	}
+	lp.formsMu.Lock()
+	if lp.forms == nil {
+		lp.forms = make(map[string]*PageLeadgenForm)
+	}
+	lp.forms[formID] = form
+	lp.formsMu.Unlock()
+	return form, nil
+}
@@ -0,0 +1,157 @@
+package fb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testTokens struct {
+	mu          sync.Mutex
+	invalidated int
+}
+
+func (tt *testTokens) Token(ctx context.Context, pageID string) (string, error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.invalidated == 0 {
+		return "old-token", nil
+	}
+	return "token-" + pageID, nil
+}
+
+func (tt *testTokens) Invalidate(pageID string) {
+	tt.mu.Lock()
+	tt.invalidated++
+	tt.mu.Unlock()
+}
+
+type testLeadSink struct {
+	mu    sync.Mutex
+	leads []*EnrichedLead
+	dead  []LeadGenEntry
+}
+
+func (ts *testLeadSink) PutLead(ctx context.Context, lead *EnrichedLead) error {
+	ts.mu.Lock()
+	ts.leads = append(ts.leads, lead)
+	ts.mu.Unlock()
+	return nil
+}
+
+func (ts *testLeadSink) PutDeadLetter(ctx context.Context, entry LeadGenEntry, err error) error {
+	ts.mu.Lock()
+	ts.dead = append(ts.dead, entry)
+	ts.mu.Unlock()
+	return nil
+}
+
+func TestLeadPipeline_Run(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") == "old-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Error validating access token","code":190,"error_subcode":463}}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/lead1":
+			w.Write([]byte(`{"id":"lead1","created_time":"2018-04-04T17:56:02+0000",` +
+				`"field_data":[{"name":"email","values":["a@example.com"]}]}`))
+		case "/" + DefaultVersion + "/form1":
+			w.Write([]byte(`{"id":"form1","name":"Form","status":"ACTIVE"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Unsupported get request.","code":100}}`))
+		}
+	})
+
+	sink := new(testLeadSink)
+	lp := &LeadPipeline{
+		Client:      c,
+		Tokens:      new(testTokens),
+		Sink:        sink,
+		DeadLetters: sink,
+		Concurrency: 1,
+		RetryDelay:  time.Millisecond,
+	}
+	entries := make(chan LeadGenEntry, 2)
+	entries <- LeadGenEntry{LeadgenID: "lead1", FormID: "form1", PageID: "page1"}
+	entries <- LeadGenEntry{LeadgenID: "lead2", FormID: "form1", PageID: "page1"}
+	close(entries)
+	if err := lp.Run(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.leads) != 1 {
+		t.Fatalf("got %d leads", len(sink.leads))
+	}
+	lead := sink.leads[0]
+	if lead.Lead.ID != "lead1" || lead.Form.Name != "Form" || lead.Entry.LeadgenID != "lead1" {
+		t.Errorf("got lead %+v with form %+v", *lead.Lead, *lead.Form)
+	}
+	if len(sink.dead) != 1 || sink.dead[0].LeadgenID != "lead2" {
+		t.Errorf("got dead letters %+v", sink.dead)
+	}
+}
+
+func TestRetryableLeadErr(t *testing.T) {
+	if retryableLeadErr(ErrPageNotFound) {
+		t.Error("ErrPageNotFound is retryable")
+	}
+	if !retryableLeadErr(&ErrResponse{Code: CodeService, HTTPStatus: http.StatusInternalServerError}) {
+		t.Error("a service error is not retryable")
+	}
+}
+
+type flakyLeadSink struct {
+	testLeadSink
+	failures int
+}
+
+func (fs *flakyLeadSink) PutLead(ctx context.Context, lead *EnrichedLead) error {
+	fs.mu.Lock()
+	if fs.failures > 0 {
+		fs.failures--
+		fs.mu.Unlock()
+		return errors.New("sink unavailable")
+	}
+	fs.mu.Unlock()
+	return fs.testLeadSink.PutLead(ctx, lead)
+}
+
+func TestLeadPipeline_SinkRetry(t *testing.T) {
+	var fetches int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/lead1":
+			atomic.AddInt32(&fetches, 1)
+			w.Write([]byte(`{"id":"lead1","created_time":"2018-04-04T17:56:02+0000","field_data":[]}`))
+		case "/" + DefaultVersion + "/form1":
+			w.Write([]byte(`{"id":"form1","name":"Form","status":"ACTIVE"}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	sink := &flakyLeadSink{failures: 2}
+	lp := &LeadPipeline{
+		Client:      c,
+		Tokens:      &testTokens{invalidated: 1},
+		Sink:        sink,
+		DeadLetters: sink,
+		RetryDelay:  time.Millisecond,
+	}
+	if err := lp.Process(context.Background(), LeadGenEntry{LeadgenID: "lead1", FormID: "form1", PageID: "page1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.leads) != 1 || len(sink.dead) != 0 {
+		t.Errorf("got %d leads and %d dead letters", len(sink.leads), len(sink.dead))
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("got %d fetches of the lead; expected the sink alone to be retried", n)
+	}
+}
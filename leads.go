@@ -0,0 +1,182 @@
+package fb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NormalizeFieldName gives the canonical form of a lead form field name so that names can be compared: it is in
+// lower case, and each run of characters other than letters and digits is replaced with one underscore. Custom
+// questions have keys such as "What's your budget?", which becomes "what_s_your_budget".
+func NormalizeFieldName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			underscore = false
+			b.WriteRune(r)
+		} else {
+			underscore = true
+		}
+	}
+	return b.String()
+}
+
+// NormalizePhone removes the formatting from a phone number, keeping only the digits and a leading "+". A leading
+// "00" international prefix is replaced with "+".
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if r == '+' && i == 0 {
+			b.WriteRune(r)
+		} else if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	s := b.String()
+	if strings.HasPrefix(s, "00") {
+		s = "+" + s[2:]
+	}
+	return s
+}
+
+// GetAll gives all the values of the field with the given name, which is compared after normalization with
+// NormalizeFieldName. It returns nil if the lead has no such field.
+func (fl *FormLead) GetAll(name string) []string {
+	name = NormalizeFieldName(name)
+	for i := range fl.FieldData {
+		if NormalizeFieldName(fl.FieldData[i].Name) == name {
+			return fl.FieldData[i].Values
+		}
+	}
+	return nil
+}
+
+// Get gives the first value of the field with the given name, or an empty string if there is none.
+func (fl *FormLead) Get(name string) string {
+	if values := fl.GetAll(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Email gives the "email" field in lower case.
+func (fl *FormLead) Email() string {
+	return strings.ToLower(strings.TrimSpace(fl.Get("email")))
+}
+
+// Phone gives the "phone_number" field normalized with NormalizePhone.
+func (fl *FormLead) Phone() string {
+	return NormalizePhone(fl.Get("phone_number"))
+}
+
+// FullName gives the "full_name" field or, if it is not given, the "first_name" and "last_name" fields joined.
+func (fl *FormLead) FullName() string {
+	if name := strings.TrimSpace(fl.Get("full_name")); name != "" {
+		return name
+	}
+	return strings.TrimSpace(strings.TrimSpace(fl.Get("first_name")) + " " + strings.TrimSpace(fl.Get("last_name")))
+}
+
+// ToMap gives the fields of the lead keyed by the normalized field names. Fields with multiple values (such as
+// answers to multiple choice questions) have their values joined with commas.
+func (fl *FormLead) ToMap() map[string]string {
+	m := make(map[string]string, len(fl.FieldData))
+	for i := range fl.FieldData {
+		m[NormalizeFieldName(fl.FieldData[i].Name)] = strings.Join(fl.FieldData[i].Values, ",")
+	}
+	return m
+}
+
+// DecodeLead copies the fields of the lead into the struct that v points to. Each struct field to fill must have
+// a tag with the key "fblead" giving the name of the lead field, optionally followed by ",phone" to normalize the
+// value with NormalizePhone. For example:
+//
+//	type Contact struct {
+//		Email  string   `fblead:"email"`
+//		Phone  string   `fblead:"phone_number,phone"`
+//		Budget int      `fblead:"what's_your_budget?"`
+//		Topics []string `fblead:"topics"`
+//	}
+//
+// Struct fields may be of type string, []string, bool, or any integer or floating-point type. Struct fields for
+// which the lead has no value are left unchanged.
+func DecodeLead(lead *FormLead, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("fb: DecodeLead requires a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("fblead")
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		name, opt, _ := strings.Cut(tag, ",")
+		values := lead.GetAll(name)
+		if len(values) == 0 {
+			continue
+		}
+		if opt == "phone" {
+			normalized := make([]string, len(values))
+			for j := range values {
+				normalized[j] = NormalizePhone(values[j])
+			}
+			values = normalized
+		}
+		if err := setLeadField(rv.Field(i), values); err != nil {
+			return fmt.Errorf("fb: cannot decode lead field %q into %s: %v", name, sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func setLeadField(f reflect.Value, values []string) error {
+	s := strings.TrimSpace(values[0])
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(values[0])
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.String {
+			return errors.New("unsupported slice type " + f.Type().String())
+		}
+		f.Set(reflect.ValueOf(append([]string(nil), values...)).Convert(f.Type()))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return errors.New("unsupported type " + f.Type().String())
+	}
+	return nil
+}
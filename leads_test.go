@@ -0,0 +1,85 @@
+package fb
+
+import "testing"
+
+func TestNormalizeFieldName(t *testing.T) {
+	cases := map[string]string{
+		"email":                "email",
+		" What's your budget?": "what_s_your_budget",
+		"PHONE-NUMBER":         "phone_number",
+		"__a  b__":             "a_b",
+	}
+	for name, expected := range cases {
+		if got := NormalizeFieldName(name); got != expected {
+			t.Errorf("got %q for %q; expected %q", got, name, expected)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	cases := map[string]string{
+		"+1 (555) 010-9999": "+15550109999",
+		"0049 30 1234567":   "+49301234567",
+		"555.010.9999":      "5550109999",
+	}
+	for phone, expected := range cases {
+		if got := NormalizePhone(phone); got != expected {
+			t.Errorf("got %q for %q; expected %q", got, phone, expected)
+		}
+	}
+}
+
+func TestFormLead_Accessors(t *testing.T) {
+	lead := &FormLead{
+		FieldData: []FieldDatum{
+			{"email", []string{" Some.One@Example.com"}},
+			{"phone_number", []string{"+1 (555) 010-9999"}},
+			{"first_name", []string{"Some"}},
+			{"last_name", []string{"One"}},
+			{"What's your budget?", []string{"5000"}},
+			{"topics", []string{"a", "b"}},
+		},
+	}
+	if got := lead.Email(); got != "some.one@example.com" {
+		t.Errorf("got email %q", got)
+	}
+	if got := lead.Phone(); got != "+15550109999" {
+		t.Errorf("got phone %q", got)
+	}
+	if got := lead.FullName(); got != "Some One" {
+		t.Errorf("got full name %q", got)
+	}
+	if got := lead.Get("what_s_your_budget"); got != "5000" {
+		t.Errorf("got budget %q", got)
+	}
+	if got := lead.GetAll("missing"); got != nil {
+		t.Errorf("got %v for a missing field", got)
+	}
+	if got := lead.ToMap()["topics"]; got != "a,b" {
+		t.Errorf("got topics %q in map", got)
+	}
+
+	var contact struct {
+		Email   string   `fblead:"email"`
+		Phone   string   `fblead:"phone_number,phone"`
+		Budget  int      `fblead:"what's your budget?"`
+		Topics  []string `fblead:"topics"`
+		Missing string   `fblead:"missing"`
+		Other   string
+	}
+	contact.Missing = "kept"
+	if err := DecodeLead(lead, &contact); err != nil {
+		t.Fatal(err)
+	}
+	if contact.Email != " Some.One@Example.com" || contact.Phone != "+15550109999" || contact.Budget != 5000 ||
+		len(contact.Topics) != 2 || contact.Missing != "kept" {
+		t.Errorf("got decoded struct %+v", contact)
+	}
+
+	var bad struct {
+		Budget bool `fblead:"what's your budget?"`
+	}
+	if err := DecodeLead(lead, &bad); err == nil {
+		t.Error("expected an error decoding into a bool")
+	}
+}
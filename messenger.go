@@ -0,0 +1,213 @@
+package fb
+
+import "context"
+
+// A MessagingEvent is a Messenger platform webhook event, given in the "messaging" or "standby" array of an entry.
+// Exactly one of the event fields (Message, Postback, Delivery, etc.) is set; Kind tells which one.
+// Info: https://developers.facebook.com/docs/messenger-platform/webhooks
+type MessagingEvent struct {
+	Sender    MessagingParty `json:"sender"`
+	Recipient MessagingParty `json:"recipient"`
+	Timestamp int64          `json:"timestamp"` // A Unix timestamp in milliseconds.
+
+	Message              *MessengerMessage      `json:"message"`
+	Postback             *MessengerPostback     `json:"postback"`
+	Delivery             *MessengerDelivery     `json:"delivery"`
+	Read                 *MessengerRead         `json:"read"`
+	Referral             *MessengerReferral     `json:"referral"`
+	Optin                *MessengerOptin        `json:"optin"`
+	PassThreadControl    *PassThreadControl     `json:"pass_thread_control"`
+	TakeThreadControl    *TakeThreadControl     `json:"take_thread_control"`
+	RequestThreadControl *RequestThreadControl  `json:"request_thread_control"`
+	AppRoles             map[string][]string    `json:"app_roles"` // Keyed by app ID.
+	PolicyEnforcement    *MessengerPolicyAction `json:"policy-enforcement"`
+}
+
+// Kinds of messaging events, as given by the Kind method of MessagingEvent.
+const (
+	MessagingKindMessage              = "message"
+	MessagingKindEcho                 = "message_echo"
+	MessagingKindPostback             = "postback"
+	MessagingKindDelivery             = "delivery"
+	MessagingKindRead                 = "read"
+	MessagingKindReferral             = "referral"
+	MessagingKindOptin                = "optin"
+	MessagingKindPassThreadControl    = "pass_thread_control"
+	MessagingKindTakeThreadControl    = "take_thread_control"
+	MessagingKindRequestThreadControl = "request_thread_control"
+	MessagingKindAppRoles             = "app_roles"
+	MessagingKindPolicyEnforcement    = "policy_enforcement"
+)
+
+// Kind says which kind of event this is. An empty string is returned for an event of a kind not modeled here.
+func (me *MessagingEvent) Kind() string {
+	switch {
+	case me.Message != nil && me.Message.IsEcho:
+		return MessagingKindEcho
+	case me.Message != nil:
+		return MessagingKindMessage
+	case me.Postback != nil:
+		return MessagingKindPostback
+	case me.Delivery != nil:
+		return MessagingKindDelivery
+	case me.Read != nil:
+		return MessagingKindRead
+	case me.Referral != nil:
+		return MessagingKindReferral
+	case me.Optin != nil:
+		return MessagingKindOptin
+	case me.PassThreadControl != nil:
+		return MessagingKindPassThreadControl
+	case me.TakeThreadControl != nil:
+		return MessagingKindTakeThreadControl
+	case me.RequestThreadControl != nil:
+		return MessagingKindRequestThreadControl
+	case me.AppRoles != nil:
+		return MessagingKindAppRoles
+	case me.PolicyEnforcement != nil:
+		return MessagingKindPolicyEnforcement
+	}
+	return ""
+}
+
+// A MessagingParty identifies the sender or recipient of a messaging event. The ID is a page-scoped user ID or a
+// page ID. For events from the checkbox plugin, only UserRef is given.
+type MessagingParty struct {
+	ID      string `json:"id"`
+	UserRef string `json:"user_ref"`
+}
+
+// A MessengerMessage is a message sent to the page or, if IsEcho is true, sent by the page.
+type MessengerMessage struct {
+	Mid         string                `json:"mid"`
+	Text        string                `json:"text"`
+	QuickReply  *MessengerQuickReply  `json:"quick_reply"`
+	ReplyTo     *MessengerReplyTo     `json:"reply_to"`
+	Attachments []MessengerAttachment `json:"attachments"`
+
+	// Set only for echo messages.
+	IsEcho   bool   `json:"is_echo"`
+	AppID    int64  `json:"app_id"`
+	Metadata string `json:"metadata"`
+}
+
+// A MessengerQuickReply gives the payload of the quick reply button the user tapped.
+type MessengerQuickReply struct {
+	Payload string `json:"payload"`
+}
+
+// A MessengerReplyTo identifies the message replied to.
+type MessengerReplyTo struct {
+	Mid string `json:"mid"`
+}
+
+// A MessengerAttachment is a file, location, template, or fallback attachment of a message.
+type MessengerAttachment struct {
+	Type    string                     `json:"type"` // enum{audio, file, image, location, video, fallback, template}
+	Title   string                     `json:"title"`
+	URL     string                     `json:"url"`
+	Payload MessengerAttachmentPayload `json:"payload"`
+}
+
+// A MessengerAttachmentPayload gives the content of an attachment. Which fields are set depends on the type.
+type MessengerAttachmentPayload struct {
+	URL          string `json:"url"`
+	Title        string `json:"title"`
+	StickerID    int64  `json:"sticker_id"`
+	TemplateType string `json:"template_type"`
+	Coordinates  *struct {
+		Lat  float64 `json:"lat"`
+		Long float64 `json:"long"`
+	} `json:"coordinates"`
+}
+
+// A MessengerPostback is given when the user taps a postback button, Get Started button, or persistent menu item.
+type MessengerPostback struct {
+	Mid      string             `json:"mid"`
+	Title    string             `json:"title"`
+	Payload  string             `json:"payload"`
+	Referral *MessengerReferral `json:"referral"` // Set if the user entered the thread with a referral.
+}
+
+// A MessengerDelivery says that the messages sent by the page were delivered.
+type MessengerDelivery struct {
+	Mids      []string `json:"mids"`
+	Watermark int64    `json:"watermark"` // All messages sent before this time were delivered.
+}
+
+// A MessengerRead says that the messages sent by the page were read.
+type MessengerRead struct {
+	Watermark int64 `json:"watermark"` // All messages sent before this time were read.
+}
+
+// A MessengerReferral says how the user got to the thread, such as through an m.me link or an ad.
+type MessengerReferral struct {
+	Ref        string `json:"ref"`
+	Source     string `json:"source"` // enum{SHORTLINK, ADS, MESSENGER_CODE, DISCOVER_TAB, CUSTOMER_CHAT_PLUGIN, ...}
+	Type       string `json:"type"`   // enum{OPEN_THREAD}
+	AdID       string `json:"ad_id"`
+	RefererURI string `json:"referer_uri"`
+}
+
+// A MessengerOptin is given when the user opts in through a plugin or a one-time notification request.
+type MessengerOptin struct {
+	Ref     string `json:"ref"`
+	UserRef string `json:"user_ref"`
+	Type    string `json:"type"`
+	Payload string `json:"payload"`
+
+	// Set for one-time notification opt-ins.
+	OneTimeNotifToken string `json:"one_time_notif_token"`
+}
+
+// A PassThreadControl says that thread control was passed to the app by the app with PreviousOwnerAppID.
+type PassThreadControl struct {
+	PreviousOwnerAppID string `json:"previous_owner_app_id"`
+	NewOwnerAppID      string `json:"new_owner_app_id"`
+	Metadata           string `json:"metadata"`
+}
+
+// A TakeThreadControl says that thread control was taken from the app.
+type TakeThreadControl struct {
+	PreviousOwnerAppID string `json:"previous_owner_app_id"`
+	NewOwnerAppID      string `json:"new_owner_app_id"`
+	Metadata           string `json:"metadata"`
+}
+
+// A RequestThreadControl says that another app requested thread control from the app.
+type RequestThreadControl struct {
+	RequestedOwnerAppID string `json:"requested_owner_app_id"`
+	Metadata            string `json:"metadata"`
+}
+
+// A MessengerPolicyAction says that a policy enforcement action was taken on the page.
+type MessengerPolicyAction struct {
+	Action string `json:"action"` // enum{warning, block, unblock}
+	Reason string `json:"reason"`
+}
+
+// A MessagingEventHandler handles a messaging event given in an entry of a webhook notification.
+type MessagingEventHandler func(ctx context.Context, entry *WebhookEntry, ev *MessagingEvent) error
+
+// HandleMessaging registers the handler for the events given in the "messaging" array of entries.
+func (wr *WebhookRouter) HandleMessaging(h MessagingEventHandler) {
+	wr.messaging = h
+}
+
+// HandleStandby registers the handler for the events given in the "standby" array of entries, which are the events
+// of threads that the app does not control under the handover protocol.
+func (wr *WebhookRouter) HandleStandby(h MessagingEventHandler) {
+	wr.standby = h
+}
+
+func dispatchMessaging(ctx context.Context, h MessagingEventHandler, entry *WebhookEntry, events []MessagingEvent) error {
+	if h == nil {
+		return nil
+	}
+	for i := range events {
+		if err := h(ctx, entry, &events[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestMessagingEvent_Kind(t *testing.T) {
+	const payload = `{"object":"page","entry":[{"id":"123","time":1458692752478,"messaging":[` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"timestamp":1458692752478,"message":{"mid":"m1","text":"hi",` +
+		`"quick_reply":{"payload":"RED"},"attachments":[{"type":"location","payload":{"coordinates":{"lat":1.5,"long":2}}}]}},` +
+		`{"sender":{"id":"123"},"recipient":{"id":"5"},"message":{"mid":"m2","is_echo":true,"app_id":1517776481860111}},` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"postback":{"title":"Start","payload":"GET_STARTED"}},` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"delivery":{"mids":["m2"],"watermark":1458668856253}},` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"read":{"watermark":1458668856253}},` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"referral":{"ref":"x","source":"SHORTLINK","type":"OPEN_THREAD"}},` +
+		`{"sender":{"user_ref":"u"},"recipient":{"id":"123"},"optin":{"ref":"x","user_ref":"u"}},` +
+		`{"sender":{"id":"5"},"recipient":{"id":"123"},"pass_thread_control":{"new_owner_app_id":"1"}}],` +
+		`"standby":[{"sender":{"id":"5"},"recipient":{"id":"123"},"message":{"mid":"m3","text":"hello"}}]}]}`
+	n := new(WebhookNotif)
+	if err := json.Unmarshal([]byte(payload), n); err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds, standby []string
+	wr := new(WebhookRouter)
+	wr.HandleMessaging(func(ctx context.Context, entry *WebhookEntry, ev *MessagingEvent) error {
+		kinds = append(kinds, ev.Kind())
+		return nil
+	})
+	wr.HandleStandby(func(ctx context.Context, entry *WebhookEntry, ev *MessagingEvent) error {
+		standby = append(standby, ev.Message.Text)
+		return nil
+	})
+	if err := wr.Dispatch(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{MessagingKindMessage, MessagingKindEcho, MessagingKindPostback, MessagingKindDelivery,
+		MessagingKindRead, MessagingKindReferral, MessagingKindOptin, MessagingKindPassThreadControl}
+	if len(kinds) != len(expected) {
+		t.Fatalf("got kinds %v", kinds)
+	}
+	for i := range kinds {
+		if kinds[i] != expected[i] {
+			t.Errorf("event %d: got kind %q; expected %q", i, kinds[i], expected[i])
+		}
+	}
+	if len(standby) != 1 || standby[0] != "hello" {
+		t.Errorf("got standby messages %v", standby)
+	}
+
+	msg := n.Entry[0].Messaging[0].Message
+	if msg.QuickReply == nil || msg.QuickReply.Payload != "RED" {
+		t.Errorf("got quick reply %+v", msg.QuickReply)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Payload.Coordinates == nil ||
+		msg.Attachments[0].Payload.Coordinates.Lat != 1.5 {
+		t.Errorf("got attachments %+v", msg.Attachments)
+	}
+}
@@ -0,0 +1,162 @@
+package fb
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDialogURL is the host of the login dialog used by LoginDialog.
+var DefaultDialogURL = &url.URL{Scheme: "https", Host: "www.facebook.com"}
+
+// A LoginDialog holds the parameters of the login dialog that starts the OAuth login flow for a user.
+// Info: https://developers.facebook.com/docs/facebook-login/manually-build-a-login-flow
+type LoginDialog struct {
+	AppID       string
+	RedirectURI string   // Must match a Valid OAuth Redirect URI of the app.
+	Scope       []string // The permissions to ask for.
+	State       string   // A value to protect against CSRF; see StateSigner.
+
+	// ResponseType says how the response is given with the redirect; if empty, "code" is used. Use "token" for the
+	// client-side flow.
+	ResponseType string
+
+	// If Rerequest is set, the dialog asks again for permissions that the user declined before.
+	Rerequest bool
+
+	Version string // The Graph API version, such as "v2.12"; if empty, DefaultVersion is used.
+}
+
+// URL gives the URL of the login dialog to redirect the user to.
+func (ld *LoginDialog) URL() string {
+	version := ld.Version
+	if version == "" {
+		version = DefaultVersion
+	}
+	responseType := ld.ResponseType
+	if responseType == "" {
+		responseType = "code"
+	}
+	v := url.Values{
+		"client_id":     {ld.AppID},
+		"redirect_uri":  {ld.RedirectURI},
+		"response_type": {responseType},
+	}
+	if len(ld.Scope) > 0 {
+		v.Set("scope", strings.Join(ld.Scope, ","))
+	}
+	if ld.State != "" {
+		v.Set("state", ld.State)
+	}
+	if ld.Rerequest {
+		v.Set("auth_type", "rerequest")
+	}
+	u := *DefaultDialogURL
+	u.Path = "/" + version + "/dialog/oauth"
+	u.RawQuery = v.Encode()
+	return u.String()
+}
+
+// CodeExchangeReq sets up an http.Request for exchanging the code given to the redirect URI by the login dialog for
+// a user access token. The redirectURI must be the same as the one given to the login dialog. Use the TokenResponse
+// type for responses.
+func CodeExchangeReq(appID, appSecret, redirectURI, code string) *http.Request {
+	return Req(http.MethodGet, "oauth/access_token", "", nil,
+		&ParamStrStr{"client_id", appID},
+		&ParamStrStr{"client_secret", appSecret},
+		&ParamStrStr{"redirect_uri", redirectURI},
+		&ParamStrStr{"code", code})
+}
+
+// ExchangeCode exchanges the code given to the redirect URI by the login dialog for a user access token. The
+// token is short-lived; use ExtendedUserAccessTokenReq to get a long-lived token.
+func (c *Client) ExchangeCode(ctx context.Context, appID, appSecret, redirectURI, code string) (*TokenResponse, error) {
+	resp, err := c.DoContext(ctx, c.Rebase(CodeExchangeReq(appID, appSecret, redirectURI, code)))
+	if err != nil {
+		return nil, err
+	}
+	tr := new(TokenResponse)
+	if err := c.DecodeResponse(resp, tr); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// Errors returned by StateSigner.Verify.
+var (
+	ErrStateInvalid = errors.New("fb: invalid OAuth state")
+	ErrStateExpired = errors.New("fb: expired OAuth state")
+)
+
+// ErrStateKey is returned by StateSigner.New if the key is shorter than MinStateKeySize.
+var ErrStateKey = errors.New("fb: OAuth state key too short")
+
+// MinStateKeySize is the minimum size of the key of a StateSigner.
+const MinStateKeySize = 32
+
+// A StateSigner generates and verifies the values of the state parameter of the login dialog. Each state has a random
+// nonce and the time it was made, signed with an HMAC bound to the session of the user, so that a state cannot be
+// forged or used for a different session.
+type StateSigner struct {
+	Key    []byte        // The secret key for the HMAC; it must be at least MinStateKeySize random bytes.
+	MaxAge time.Duration // How long a state is valid; if zero, 10 minutes is used.
+}
+
+// New generates a state for the session, which should be an identifier of the session of the user (such as a
+// session ID kept in a cookie).
+func (ss *StateSigner) New(session string) (string, error) {
+	if len(ss.Key) < MinStateKeySize {
+		return "", ErrStateKey
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(time.Now().Unix(), 10)
+	return payload + "." + ss.sign(payload, session), nil
+}
+
+// Verify checks that the state was generated by New for the session and has not expired. No state is valid if the
+// key is shorter than MinStateKeySize.
+func (ss *StateSigner) Verify(state, session string) error {
+	i := strings.LastIndexByte(state, '.')
+	if i < 0 || len(ss.Key) < MinStateKeySize {
+		return ErrStateInvalid
+	}
+	payload, sig := state[:i], state[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(ss.sign(payload, session))) {
+		return ErrStateInvalid
+	}
+	j := strings.IndexByte(payload, '.')
+	if j < 0 {
+		return ErrStateInvalid
+	}
+	issued, err := strconv.ParseInt(payload[j+1:], 10, 64)
+	if err != nil {
+		return ErrStateInvalid
+	}
+	maxAge := ss.MaxAge
+	if maxAge <= 0 {
+		maxAge = 10 * time.Minute
+	}
+	if time.Since(time.Unix(issued, 0)) > maxAge {
+		return ErrStateExpired
+	}
+	return nil
+}
+
+func (ss *StateSigner) sign(payload, session string) string {
+	mac := hmac.New(sha256.New, ss.Key)
+	mac.Write([]byte(payload))
+	mac.Write([]byte{0})
+	mac.Write([]byte(session))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package fb
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginDialog_URL(t *testing.T) {
+	ld := &LoginDialog{
+		AppID:       "123",
+		RedirectURI: "https://example.com/callback",
+		Scope:       []string{"email", "leads_retrieval"},
+		State:       "xyz",
+		Rerequest:   true,
+	}
+	expected := "https://www.facebook.com/" + DefaultVersion + "/dialog/oauth?auth_type=rerequest&client_id=123" +
+		"&redirect_uri=https%3A%2F%2Fexample.com%2Fcallback&response_type=code&scope=email%2Cleads_retrieval&state=xyz"
+	if got := ld.URL(); got != expected {
+		t.Errorf("got URL %q", got)
+	}
+}
+
+func TestClient_ExchangeCode(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/"+DefaultVersion+"/oauth/access_token" || q.Get("code") != "the-code" ||
+			q.Get("client_id") != "123" || q.Get("client_secret") != "secret" || q.Get("access_token") != "" {
+			t.Errorf("got unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"access_token":"user-token","token_type":"bearer","expires_in":5183944}`))
+	})
+
+	tr, err := c.ExchangeCode(context.Background(), "123", "secret", "https://example.com/callback", "the-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.AccessToken != "user-token" || tr.ExpiresIn != 5183944 {
+		t.Errorf("got token response %+v", *tr)
+	}
+}
+
+func TestStateSigner(t *testing.T) {
+	ss := &StateSigner{Key: []byte("0123456789abcdef0123456789abcdef")}
+	state, err := ss.New("session1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.Verify(state, "session1"); err != nil {
+		t.Errorf("got error %v for a valid state", err)
+	}
+	if err := ss.Verify(state, "session2"); err != ErrStateInvalid {
+		t.Errorf("got error %v for another session", err)
+	}
+	if err := ss.Verify(state+"x", "session1"); err != ErrStateInvalid {
+		t.Errorf("got error %v for a tampered state", err)
+	}
+	if err := ss.Verify("garbage", "session1"); err != ErrStateInvalid {
+		t.Errorf("got error %v for garbage", err)
+	}
+
+	payload := "bm9uY2U." + strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	old := payload + "." + ss.sign(payload, "session1")
+	if err := ss.Verify(old, "session1"); err != ErrStateExpired {
+		t.Errorf("got error %v for an old state", err)
+	}
+	if strings.Count(state, ".") != 2 {
+		t.Errorf("got state %q", state)
+	}
+}
+
+func TestStateSigner_ShortKey(t *testing.T) {
+	// With an empty key, anyone could compute the signature of a state.
+	ss := new(StateSigner)
+	if _, err := ss.New("session1"); err != ErrStateKey {
+		t.Errorf("got error %v for an empty key", err)
+	}
+	payload := "bm9uY2U." + strconv.FormatInt(time.Now().Unix(), 10)
+	if err := ss.Verify(payload+"."+ss.sign(payload, "session1"), "session1"); err != ErrStateInvalid {
+		t.Errorf("got error %v for a state signed with an empty key", err)
+	}
+	ss.Key = []byte("short")
+	if _, err := ss.New("session1"); err != ErrStateKey {
+		t.Errorf("got error %v for a short key", err)
+	}
+}
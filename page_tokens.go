@@ -0,0 +1,211 @@
+package fb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrPageNotFound is returned by a PageTokenProvider for a page that the user has no role on.
+var ErrPageNotFound = errors.New("fb: page not found for the user")
+
+// A PageTokenProvider gives the page access tokens derived from a long-lived user access token. The tokens of all the
+// pages of the user are listed at once and cached by page ID; page tokens derived from a long-lived user token do not
+// expire, so they are listed again only for a page that is not known or whose token was invalidated. Concurrent
+// lookups that need the pages listed again share a single listing, and pages that the listing does not include are
+// remembered for NotFoundTTL so that lookups of an unknown page do not each list all the pages.
+//
+// A PageTokenProvider is a PageTokenInvalidator, so it can be used as the Tokens of a LeadPipeline. It is safe for
+// concurrent use.
+type PageTokenProvider struct {
+	Client          *Client // If nil, DefaultClient is used.
+	UserAccessToken string
+
+	// Store, if set, persists the page tokens with the TokenKindPage kind, so that they need not be listed again
+	// after a restart.
+	Store TokenStore
+
+	// NotFoundTTL is how long a page that was not found is reported as not found without listing the pages again;
+	// if zero, 1 minute is used.
+	NotFoundTTL time.Duration
+
+	mu       sync.Mutex
+	tokens   map[string]string
+	stale    map[string]bool      // Pages whose token in the Store must not be used.
+	notFound map[string]time.Time // Pages not found, with the time until which they are reported as not found.
+	listing  *pageListing         // The listing in progress, if any.
+}
+
+// A pageListing is a listing of the pages of the user that lookups can wait for.
+type pageListing struct {
+	done chan struct{}
+	err  error
+}
+
+func (p *PageTokenProvider) client() *Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return DefaultClient
+}
+
+// Token gives the page access token for the page, or ErrPageNotFound if the user has no role on the page.
+func (p *PageTokenProvider) Token(ctx context.Context, pageID string) (string, error) {
+	p.mu.Lock()
+	if token, ok := p.tokens[pageID]; ok {
+		p.mu.Unlock()
+		return token, nil
+	}
+	if until, ok := p.notFound[pageID]; ok && time.Now().Before(until) {
+		p.mu.Unlock()
+		return "", ErrPageNotFound
+	}
+	useStore := p.Store != nil && !p.stale[pageID]
+	p.mu.Unlock()
+
+	if useStore {
+		st, err := p.Store.Token(ctx, TokenKey{TokenKindPage, pageID})
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			return "", err
+		}
+		if err == nil && !st.Invalid {
+			p.mu.Lock()
+			p.cache(pageID, st.AccessToken)
+			p.mu.Unlock()
+			return st.AccessToken, nil
+		}
+	}
+
+	if err := p.list(ctx); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if token, ok := p.tokens[pageID]; ok {
+		return token, nil
+	}
+	ttl := p.NotFoundTTL
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	if p.notFound == nil {
+		p.notFound = make(map[string]time.Time)
+	}
+	p.notFound[pageID] = time.Now().Add(ttl)
+	return "", ErrPageNotFound
+}
+
+// cache records the token of the page; p.mu must be held.
+func (p *PageTokenProvider) cache(pageID, token string) {
+	if p.tokens == nil {
+		p.tokens = make(map[string]string)
+	}
+	p.tokens[pageID] = token
+	delete(p.notFound, pageID)
+}
+
+// list lists the pages of the user, caching their tokens and saving them in the Store. If a listing is already in
+// progress, list waits for it instead. A listing made with the context of another lookup may fail because that
+// context is done, so in that case list starts over if ctx is not done.
+func (p *PageTokenProvider) list(ctx context.Context) error {
+	p.mu.Lock()
+	for l := p.listing; l != nil; l = p.listing {
+		p.mu.Unlock()
+		select {
+		case <-l.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if l.err == nil || ctx.Err() != nil ||
+			!(errors.Is(l.err, context.Canceled) || errors.Is(l.err, context.DeadlineExceeded)) {
+			return l.err
+		}
+		p.mu.Lock()
+	}
+	l := &pageListing{done: make(chan struct{})}
+	p.listing = l
+	p.mu.Unlock()
+
+	l.err = p.listPages(ctx)
+	p.mu.Lock()
+	p.listing = nil
+	p.mu.Unlock()
+	close(l.done)
+	return l.err
+}
+
+func (p *PageTokenProvider) listPages(ctx context.Context) error {
+	c := p.client()
+	pages := NewCursorIter[UserPage](ctx, c, ListUserPagesFieldsReq(p.UserAccessToken, []string{"id", "access_token"}))
+	tokens := make(map[string]string)
+	for pages.Next() {
+		if page := pages.Item(); page.AccessToken != "" {
+			tokens[page.ID] = page.AccessToken
+		}
+	}
+	if err := pages.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	for pageID, token := range tokens {
+		p.cache(pageID, token)
+		delete(p.stale, pageID)
+	}
+	p.mu.Unlock()
+
+	if p.Store != nil {
+		now := time.Now()
+		for pageID, token := range tokens {
+			st := &StoredToken{AccessToken: token, UpdatedAt: now}
+			if err := p.Store.PutToken(ctx, TokenKey{TokenKindPage, pageID}, st); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Invalidate drops the token of the page so that the pages of the user are listed again the next time the token is
+// needed.
+func (p *PageTokenProvider) Invalidate(pageID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tokens, pageID)
+	if p.Store != nil {
+		if p.stale == nil {
+			p.stale = make(map[string]bool)
+		}
+		p.stale[pageID] = true
+	}
+}
+
+// Call makes the request that build sets up with the access token of the page and decodes the response into v. If
+// Facebook replies that the token is invalid (error code 190), the token is invalidated and the request is made once
+// more with a token listed again. The request is adapted to the client with Client.Rebase, so use Call with request
+// builders such as SubscribeAppToPageReq and FormLeadsReq:
+//
+//	err := p.Call(ctx, pageID, func(token string) *http.Request {
+//		return fb.FormLeadsReq(token, formID)
+//	}, leads)
+func (p *PageTokenProvider) Call(ctx context.Context, pageID string, build func(token string) *http.Request, v interface{}) error {
+	c := p.client()
+	for attempt := 0; ; attempt++ {
+		token, err := p.Token(ctx, pageID)
+		if err != nil {
+			return err
+		}
+		resp, err := c.DoContext(ctx, c.Rebase(build(token)))
+		if err != nil {
+			return err
+		}
+		err = c.DecodeResponse(resp, v)
+		if attempt == 0 && IsTokenInvalid(err) {
+			p.Invalidate(pageID)
+			continue
+		}
+		return err
+	}
+}
@@ -0,0 +1,182 @@
+package fb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPageTokenProvider(t *testing.T) {
+	lists := 0
+	pageToken := "token1"
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/me/accounts":
+			if q.Get("access_token") != "user-token" {
+				t.Errorf("got user token %q", q.Get("access_token"))
+			}
+			lists++
+			if q.Get("after") == "" {
+				w.Write([]byte(`{"data":[{"id":"p1","access_token":"` + pageToken + `"}],` +
+					`"paging":{"cursors":{"after":"c1"},"next":"` + "http://" + r.Host + r.URL.Path + `?access_token=user-token&after=c1"}}`))
+				return
+			}
+			w.Write([]byte(`{"data":[{"id":"p2","access_token":"token2"}]}`))
+		case "/" + DefaultVersion + "/p1/subscribed_apps":
+			token := r.FormValue("access_token")
+			if token != "token1" && token != "token1-new" {
+				t.Errorf("got page token %q", token)
+			}
+			if token == "token1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"message":"Error validating access token","type":"OAuthException","code":190}}`))
+				return
+			}
+			w.Write([]byte(`{"success":true}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	ctx := context.Background()
+	store := new(MemoryTokenStore)
+	p := &PageTokenProvider{Client: c, UserAccessToken: "user-token", Store: store}
+	var _ PageTokenInvalidator = p
+
+	token, err := p.Token(ctx, "p2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token2" {
+		t.Errorf("got token %q for the page on the second page of results", token)
+	}
+	if _, err := p.Token(ctx, "p1"); err != nil || lists != 2 {
+		t.Errorf("got error %v and %d list requests; expected the token to be cached", err, lists)
+	}
+	if st, err := store.Token(ctx, TokenKey{TokenKindPage, "p1"}); err != nil || st.AccessToken != "token1" {
+		t.Errorf("got stored token %v and error %v", st, err)
+	}
+	lists = 0
+	for i := 0; i < 2; i++ {
+		if _, err := p.Token(ctx, "p3"); err != ErrPageNotFound {
+			t.Errorf("got error %v for an unknown page", err)
+		}
+	}
+	if lists != 2 {
+		t.Errorf("got %d list requests; expected the unknown page to be remembered", lists)
+	}
+
+	// The token of p1 was revoked: Call gets a 190 error and retries with a token listed again.
+	pageToken = "token1-new"
+	lists = 0
+	resp := new(SubscribeAppResponse)
+	err = p.Call(ctx, "p1", func(token string) *http.Request {
+		return SubscribeAppToPageReq(token, "p1")
+	}, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || lists != 2 {
+		t.Errorf("got response %+v after %d list requests", *resp, lists)
+	}
+
+	// A new provider uses the tokens saved in the store.
+	lists = 0
+	p = &PageTokenProvider{Client: c, UserAccessToken: "user-token", Store: store}
+	if token, err := p.Token(ctx, "p1"); err != nil || token != "token1-new" || lists != 0 {
+		t.Errorf("got token %q, error %v and %d list requests", token, err, lists)
+	}
+}
+
+func TestPageTokenProvider_SharedListing(t *testing.T) {
+	var lists int32
+	release := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lists, 1)
+		<-release
+		w.Write([]byte(`{"data":[{"id":"p1","access_token":"token1"}]}`))
+	})
+
+	p := &PageTokenProvider{Client: c, UserAccessToken: "user-token"}
+	p.tokens = map[string]string{"p2": "token2"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := p.Token(context.Background(), "p1")
+			if err == nil && token != "token1" {
+				t.Errorf("got token %q", token)
+			}
+			errs <- err
+		}()
+	}
+
+	// A cached token is given while the listing is in progress.
+	for atomic.LoadInt32(&lists) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if token, err := p.Token(context.Background(), "p2"); err != nil || token != "token2" {
+		t.Errorf("got token %q and error %v during a listing", token, err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := atomic.LoadInt32(&lists); n != 1 {
+		t.Errorf("got %d listings; expected the lookups to share one", n)
+	}
+}
+
+func TestPageTokenProvider_ListingCanceled(t *testing.T) {
+	var lists int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&lists, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"p1","access_token":"token1"}]}`))
+	})
+
+	p := &PageTokenProvider{Client: c, UserAccessToken: "user-token"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := p.Token(ctx, "p1")
+		leaderErr <- err
+	}()
+	for atomic.LoadInt32(&lists) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A lookup waiting for the listing of a lookup whose context is canceled lists the pages again.
+	waiterErr := make(chan error, 1)
+	go func() {
+		token, err := p.Token(context.Background(), "p1")
+		if err == nil && token != "token1" {
+			t.Errorf("got token %q", token)
+		}
+		waiterErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v for the canceled lookup", err)
+	}
+	if err := <-waiterErr; err != nil {
+		t.Errorf("got error %v for the waiting lookup", err)
+	}
+}
@@ -2,8 +2,10 @@ package fb
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // A UserPagesList response lists the pages belonging to a user.
@@ -106,6 +108,22 @@ type PageLeadgenForm struct {
 	Status string `json:"status"`
 }
 
+// PageLeadgenFormReq returns a request to read the basic information about a lead form. Use the PageLeadgenForm type
+// for responses.
+// Fields retrieved: id,name,status
+func PageLeadgenFormReq(pageAccessToken, formID string) *http.Request {
+	return Req(http.MethodGet, formID, pageAccessToken, pageLeadgenFormFields)
+}
+
+var pageLeadgenFormFields = []string{"id", "name", "status"}
+
+// PageLeadgenFormsReq returns a request to list the lead forms of a page. Use the PageLeadgenFormList type for
+// responses, or NewCursorIter to page through the forms.
+// Fields retrieved: id,name,status
+func PageLeadgenFormsReq(pageAccessToken, pageID string) *http.Request {
+	return Req(http.MethodGet, pageID+"/leadgen_forms", pageAccessToken, pageLeadgenFormFields)
+}
+
 // Fields queried: created_time,id,form_id,field_data
 func FormLeadsReq(pageAccessToken, formID string) *http.Request {
 	return Req(http.MethodGet, formID+"/leads", pageAccessToken, formLeadsFields)
@@ -113,6 +131,33 @@ func FormLeadsReq(pageAccessToken, formID string) *http.Request {
 
 var formLeadsFields = []string{"created_time", "id", "form_id", "field_data"}
 
+// FormLeadsFilterReq is like FormLeadsReq but queries only the leads created after since and before until. Leave
+// either time zero to not bound the query on that side.
+func FormLeadsFilterReq(pageAccessToken, formID string, since, until time.Time) *http.Request {
+	return Req(http.MethodGet, formID+"/leads", pageAccessToken, formLeadsFields, leadsFilterParams(since, until)...)
+}
+
+// leadsFilterParams gives the filtering parameter for the leads edge to select leads created within the bounds.
+func leadsFilterParams(since, until time.Time) []Param {
+	type filter struct {
+		Field    string `json:"field"`
+		Operator string `json:"operator"`
+		Value    int64  `json:"value"`
+	}
+	var filters []filter
+	if !since.IsZero() {
+		filters = append(filters, filter{"time_created", "GREATER_THAN", since.Unix()})
+	}
+	if !until.IsZero() {
+		filters = append(filters, filter{"time_created", "LESS_THAN", until.Unix()})
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(filters)
+	return []Param{&ParamStrStr{"filtering", string(b)}}
+}
+
 func FormLeadDataReq(pageAccessToken, leadID string) *http.Request {
 	return Req(http.MethodGet, leadID, pageAccessToken, leadDataFields)
 }
@@ -127,13 +172,17 @@ type FormLeadsList struct {
 }
 
 type FormLead struct {
-	CreatedTime string `json:"created_time"`
-	ID          string `json:"id"`
-	FieldData   []struct {
-		Name   string   `json:"name"`
-		Values []string `json:"values"`
-	} `json:"field_data"`
-	Error *ErrResponse `json:"error"`
+	CreatedTime string       `json:"created_time"`
+	ID          string       `json:"id"`
+	FormID      string       `json:"form_id"`
+	FieldData   []FieldDatum `json:"field_data"`
+	Error       *ErrResponse `json:"error"`
+}
+
+// A FieldDatum is the answer to one question of a lead form.
+type FieldDatum struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
 }
 
 // MarshalJSON implements json.Marshaler for the FormLead type. This function always returns a nil error.
@@ -143,6 +192,8 @@ func (fl *FormLead) MarshalJSON() ([]byte, error) {
 	b.WriteString(strconv.Quote(fl.CreatedTime))
 	b.WriteString(`,"id":`)
 	b.WriteString(strconv.Quote(fl.ID))
+	b.WriteString(`,"form_id":`)
+	b.WriteString(strconv.Quote(fl.FormID))
 	b.WriteString(`,"field_data":[`)
 	for i := range fl.FieldData {
 		if i > 0 {
@@ -9,21 +9,19 @@ func TestFormLead_EncodeJSON(t *testing.T) {
 	}{
 		{
 			Lead: FormLead{},
-			JSON: `{"created_time":"","id":"","field_data":[]}`,
+			JSON: `{"created_time":"","id":"","form_id":"","field_data":[]}`,
 		},
 		{
 			Lead: FormLead{
 				CreatedTime: "12345",
 				ID:          "2342342342",
-				FieldData: []struct {
-					Name   string   `json:"name"`
-					Values []string `json:"values"`
-				}{
+				FormID:      "1234",
+				FieldData: []FieldDatum{
 					{"a", []string{"b"}},
 					{"c", []string{"d", "e"}},
 				},
 			},
-			JSON: `{"created_time":"12345","id":"2342342342","field_data":[{"name":"a","values":["b"]},{"name":"c","values":["d","e"]}]}`,
+			JSON: `{"created_time":"12345","id":"2342342342","form_id":"1234","field_data":[{"name":"a","values":["b"]},{"name":"c","values":["d","e"]}]}`,
 		},
 	}
 	for i, lead := range leads {
@@ -0,0 +1,357 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// A Pager moves an Iter from one page of results to the next. A Pager keeps the position of the iteration, so it
+// can be saved and used later to resume from the same place.
+//
+// The position is kept by page: an Iter advances the Pager past a page only once every item of the page has been
+// given. If the iteration stops in the middle of a page (for example because MaxItems was reached), the position is
+// the start of that page, so resuming gives again the items of the page that were already given.
+type Pager interface {
+	// Request returns the request for the next page of results, or nil if there are no more pages. The first request
+	// is the one that was given to set up the iteration.
+	Request(first *http.Request) (*http.Request, error)
+
+	// Advance records the "paging" object and number of items of a page that has been given in full.
+	Advance(paging json.RawMessage, n int) error
+}
+
+// A CursorPager follows the cursors of cursor-based pagination. Set After to resume from a saved cursor. If Facebook
+// gives a "next" URL without cursors, the URL is followed as with NextPage. After an iteration stops, After is the
+// cursor at the end of the last page given in full (or is left as it was if no page with a cursor was given in full).
+type CursorPager struct {
+	After string // The cursor after which the next page starts.
+
+	next    string // The "next" URL given for the last page read, used if no cursor is given.
+	started bool
+	done    bool
+}
+
+// Request implements Pager.
+func (cp *CursorPager) Request(first *http.Request) (*http.Request, error) {
+	switch {
+	case cp.done:
+		return nil, nil
+	case cp.After != "":
+		return withQuery(first, "after", cp.After), nil
+	case cp.next != "":
+		return withURL(first, cp.next)
+	case !cp.started:
+		cp.started = true
+		return first, nil
+	}
+	return nil, nil
+}
+
+// Advance implements Pager. There are no more pages when Facebook gives no "next" URL or the page was empty.
+func (cp *CursorPager) Advance(paging json.RawMessage, n int) error {
+	cp.started = true
+	var p CursorPaging
+	if len(paging) > 0 {
+		if err := json.Unmarshal(paging, &p); err != nil {
+			return err
+		}
+	}
+	if n > 0 && p.Cursors.After != "" {
+		cp.After = p.Cursors.After
+	}
+	if p.Next == "" || n == 0 {
+		cp.done = true
+		return nil
+	}
+	cp.next = p.Next
+	return nil
+}
+
+// A TimePager follows the "next" URLs of time-based pagination, which Facebook gives for edges such as insights and
+// feed. Set Since and Until (as Unix timestamps) to start or resume from a given time window. After each page, Since
+// and Until are set to the window of the next page, so they can be saved to resume later.
+//
+// Because time-based lists may go on indefinitely (insights give windows into the future), set StopAfter or
+// StopBefore to bound the iteration.
+type TimePager struct {
+	Since int64 // The start of the time window; zero means unspecified.
+	Until int64 // The end of the time window; zero means unspecified.
+
+	StopAfter  int64 // If not zero, stop once the window starts at or after this time.
+	StopBefore int64 // If not zero, stop once the window ends at or before this time.
+
+	next    string
+	started bool
+	done    bool
+}
+
+// Request implements Pager.
+func (tp *TimePager) Request(first *http.Request) (*http.Request, error) {
+	switch {
+	case tp.done:
+		return nil, nil
+	case tp.next != "":
+		return withURL(first, tp.next)
+	case !tp.started:
+		tp.started = true
+		r := first
+		if tp.Since != 0 {
+			r = withQuery(r, "since", strconv.FormatInt(tp.Since, 10))
+		}
+		if tp.Until != 0 {
+			r = withQuery(r, "until", strconv.FormatInt(tp.Until, 10))
+		}
+		return r, nil
+	}
+	return nil, nil
+}
+
+// Advance implements Pager. There are no more pages when Facebook gives no "next" URL, the page was empty, or the
+// window of the next page is past StopAfter or StopBefore.
+func (tp *TimePager) Advance(paging json.RawMessage, n int) error {
+	tp.started = true
+	var p TimePaging
+	if len(paging) > 0 {
+		if err := json.Unmarshal(paging, &p); err != nil {
+			return err
+		}
+	}
+	if p.Next == "" || n == 0 {
+		tp.done = true
+		return nil
+	}
+	u, err := url.Parse(p.Next)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	tp.Since, _ = strconv.ParseInt(q.Get("since"), 10, 64)
+	tp.Until, _ = strconv.ParseInt(q.Get("until"), 10, 64)
+	tp.next = p.Next
+	if tp.StopAfter != 0 && tp.Since >= tp.StopAfter {
+		tp.done = true
+	}
+	if tp.StopBefore != 0 && tp.Until != 0 && tp.Until <= tp.StopBefore {
+		tp.done = true
+	}
+	return nil
+}
+
+// An OffsetPager pages through a list by offset, which Facebook uses for some edges such as comments. Set Offset to
+// start or resume from a given position and Limit to set the page size (zero means the default size). After each
+// page given in full, Offset is the position of the next page, so it can be saved to resume later.
+type OffsetPager struct {
+	Offset int64
+	Limit  int64
+
+	done bool
+}
+
+// Request implements Pager.
+func (op *OffsetPager) Request(first *http.Request) (*http.Request, error) {
+	if op.done {
+		return nil, nil
+	}
+	r := withQuery(first, "offset", strconv.FormatInt(op.Offset, 10))
+	if op.Limit > 0 {
+		r = withQuery(r, "limit", strconv.FormatInt(op.Limit, 10))
+	}
+	return r, nil
+}
+
+// Advance implements Pager. There are no more pages when Facebook gives no "next" URL or the page was empty.
+func (op *OffsetPager) Advance(paging json.RawMessage, n int) error {
+	var p OffsetPaging
+	if len(paging) > 0 {
+		if err := json.Unmarshal(paging, &p); err != nil {
+			return err
+		}
+	}
+	op.Offset += int64(n)
+	if p.Next == "" || n == 0 {
+		op.done = true
+	}
+	return nil
+}
+
+// withURL returns a copy of the GET request r with the URL replaced by rawURL.
+func withURL(r *http.Request, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	rc := r.Clone(r.Context())
+	rc.URL = u
+	rc.Host = u.Host
+	return rc, nil
+}
+
+// withQuery returns a copy of the GET request r with the query parameter k set to v.
+func withQuery(r *http.Request, k, v string) *http.Request {
+	rc := r.Clone(r.Context())
+	u := *r.URL
+	q := u.Query()
+	q.Set(k, v)
+	u.RawQuery = q.Encode()
+	rc.URL = &u
+	return rc
+}
+
+// An Iter walks through the items of a paginated list, such as the "data" of a UserPagesList or FormLeadsList,
+// fetching the pages as needed. The type parameter T is the type of the items in the "data" array. Iteration stops
+// at the last page, when MaxItems or MaxPages is reached, when the context is done, or when there is an error
+// (including an *ErrResponse given by Facebook).
+//
+// Use an Iter like this:
+//
+//	it := fb.NewCursorIter[fb.FormLead](ctx, client, fb.FormLeadsReq(pageToken, formID))
+//	for it.Next() {
+//		lead := it.Item()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type Iter[T any] struct {
+	MaxItems int // The maximum number of items to give; zero means no limit.
+	MaxPages int // The maximum number of pages to fetch; zero means no limit.
+
+	ctx    context.Context
+	client *Client
+	first  *http.Request
+	pager  Pager
+
+	page    []T
+	paging  json.RawMessage // The "paging" object of the page, given to the pager once the page is given in full.
+	pending bool            // Whether the pager has yet to advance past the page.
+	i       int
+	nItems  int
+	nPages  int
+	item    T
+	err     error
+}
+
+// NewIter sets up an Iter that starts with the request req and uses pager to get to the following pages. If client
+// is nil, DefaultClient is used. The request is adapted to the client with Client.Rebase, so it may be set up by one
+// of the package-level functions with a Req suffix.
+func NewIter[T any](ctx context.Context, client *Client, req *http.Request, pager Pager) *Iter[T] {
+	if client == nil {
+		client = DefaultClient
+	}
+	return &Iter[T]{ctx: ctx, client: client, first: client.Rebase(req), pager: pager}
+}
+
+// NewCursorIter sets up an Iter for a list that uses cursor-based pagination. To resume from a saved cursor, use
+// NewIter with a CursorPager that has After set.
+func NewCursorIter[T any](ctx context.Context, client *Client, req *http.Request) *Iter[T] {
+	return NewIter[T](ctx, client, req, new(CursorPager))
+}
+
+// NewTimeIter sets up an Iter for a list that uses time-based pagination. To start from a given time window, bound
+// the iteration, or resume from a saved window, use NewIter with a TimePager.
+func NewTimeIter[T any](ctx context.Context, client *Client, req *http.Request) *Iter[T] {
+	return NewIter[T](ctx, client, req, new(TimePager))
+}
+
+// NewOffsetIter sets up an Iter for a list that uses offset-based pagination. To resume from a saved offset, use
+// NewIter with an OffsetPager that has Offset set.
+func NewOffsetIter[T any](ctx context.Context, client *Client, req *http.Request) *Iter[T] {
+	return NewIter[T](ctx, client, req, new(OffsetPager))
+}
+
+// Next advances to the next item, which is then available with Item. Next returns false when there are no more
+// items or there is an error.
+func (it *Iter[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.advance(); err != nil {
+		it.err = err
+		return false
+	}
+	if it.MaxItems > 0 && it.nItems >= it.MaxItems {
+		return false
+	}
+	for it.i >= len(it.page) {
+		if err := it.advance(); err != nil {
+			it.err = err
+			return false
+		}
+		if it.MaxPages > 0 && it.nPages >= it.MaxPages {
+			return false
+		}
+		ok, err := it.fetch()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !ok {
+			return false
+		}
+	}
+	it.item = it.page[it.i]
+	it.i++
+	it.nItems++
+	return true
+}
+
+// advance moves the pager past the current page if every item of the page has been given.
+func (it *Iter[T]) advance() error {
+	if !it.pending || it.i < len(it.page) {
+		return nil
+	}
+	it.pending = false
+	return it.pager.Advance(it.paging, len(it.page))
+}
+
+// fetch reads the next page. It returns false if there are no more pages.
+func (it *Iter[T]) fetch() (bool, error) {
+	if err := it.ctx.Err(); err != nil {
+		return false, err
+	}
+	req, err := it.pager.Request(it.first)
+	if err != nil || req == nil {
+		return false, err
+	}
+	if it.client.AppSecret != "" && req != it.first {
+		// The "next" URLs given by Facebook do not include the appsecret_proof parameter.
+		q := req.URL.Query()
+		setAppsecretProof(q, it.client.AppSecret)
+		req.URL.RawQuery = q.Encode()
+	}
+	resp, err := it.client.DoContext(it.ctx, req)
+	if err != nil {
+		return false, err
+	}
+	var page struct {
+		Data   []T             `json:"data"`
+		Paging json.RawMessage `json:"paging"`
+	}
+	if err := it.client.DecodeResponse(resp, &page); err != nil {
+		return false, err
+	}
+	it.nPages++
+	it.page = page.Data
+	it.paging = page.Paging
+	it.pending = true
+	it.i = 0
+	return true, nil
+}
+
+// Item gives the current item.
+func (it *Iter[T]) Item() T {
+	return it.item
+}
+
+// Err gives the error that stopped the iteration, if any.
+func (it *Iter[T]) Err() error {
+	return it.err
+}
+
+// Pages gives the number of pages fetched so far.
+func (it *Iter[T]) Pages() int {
+	return it.nPages
+}
@@ -0,0 +1,165 @@
+package fb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestCursorIter(t *testing.T) {
+	var srvURL string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprintf(w, `{"data":[{"id":"1"},{"id":"2"}],"paging":{"cursors":{"after":"c2"},"next":"%s/next"}}`, srvURL)
+		case "c2":
+			fmt.Fprintf(w, `{"data":[{"id":"3"}],"paging":{"cursors":{"after":"c3"},"next":"%s/next"}}`, srvURL)
+		case "c3":
+			w.Write([]byte(`{"data":[],"paging":{"cursors":{"after":"c3"}}}`))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("after"))
+		}
+	})
+	srvURL = c.BaseURL.String()
+
+	it := NewCursorIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "me/accounts", "abc", nil))
+	var ids string
+	for it.Next() {
+		ids += it.Item().ID
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if ids != "123" {
+		t.Errorf("got IDs %q", ids)
+	}
+	if it.Pages() != 3 {
+		t.Errorf("got %d pages", it.Pages())
+	}
+
+	pager := new(CursorPager)
+	it = NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "me/accounts", "abc", nil), pager)
+	it.MaxItems = 2
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 2 || it.Pages() != 1 || pager.After != "c2" {
+		t.Errorf("got %d items in %d pages and cursor %q with MaxItems", n, it.Pages(), pager.After)
+	}
+
+	// Stopping in the middle of a page leaves the position at the start of the page.
+	pager = new(CursorPager)
+	it = NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "me/accounts", "abc", nil), pager)
+	it.MaxItems = 1
+	for it.Next() {
+	}
+	if pager.After != "" {
+		t.Errorf("got cursor %q after stopping in the middle of the first page", pager.After)
+	}
+
+	pager = &CursorPager{After: "c2"}
+	it = NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "me/accounts", "abc", nil), pager)
+	ids = ""
+	for it.Next() {
+		ids += it.Item().ID
+	}
+	if ids != "3" || it.Err() != nil {
+		t.Errorf("got IDs %q and error %v resuming from a cursor", ids, it.Err())
+	}
+	if pager.After != "c3" {
+		t.Errorf("got cursor %q at the end of the list", pager.After)
+	}
+}
+
+func TestIter_ErrResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Invalid OAuth access token.","code":190}}`))
+	})
+
+	it := NewCursorIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "me/accounts", "abc", nil))
+	if it.Next() {
+		t.Error("expected no items")
+	}
+	if !IsTokenInvalid(it.Err()) {
+		t.Errorf("got error %v", it.Err())
+	}
+}
+
+func TestTimeIter(t *testing.T) {
+	var srvURL string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		until, _ := strconv.ParseInt(r.URL.Query().Get("until"), 10, 64)
+		if until-since != 100 {
+			t.Errorf("got window from %d to %d", since, until)
+		}
+		fmt.Fprintf(w, `{"data":[{"id":"%d"}],"paging":{"next":"%s/insights?since=%d&until=%d"}}`,
+			since, srvURL, until, until+100)
+	})
+	srvURL = c.BaseURL.String()
+
+	pager := &TimePager{Since: 1000, Until: 1100, StopAfter: 1300}
+	it := NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "123/insights", "abc", nil), pager)
+	var ids string
+	for it.Next() {
+		ids += it.Item().ID + ","
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if ids != "1000,1100,1200," {
+		t.Errorf("got IDs %q", ids)
+	}
+	if pager.Since != 1300 || pager.Until != 1400 {
+		t.Errorf("got the saved window from %d to %d", pager.Since, pager.Until)
+	}
+}
+
+func TestOffsetIter(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset >= 5 {
+			w.Write([]byte(`{"data":[],"paging":{}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data":[{"id":"%d"},{"id":"%d"}],"paging":{"next":"more"}}`, offset, offset+1)
+	})
+
+	pager := &OffsetPager{Offset: 1, Limit: 2}
+	it := NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "123/comments", "abc", nil), pager)
+	var ids string
+	for it.Next() {
+		ids += it.Item().ID
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if ids != "1234" || pager.Offset != 5 {
+		t.Errorf("got IDs %q and offset %d", ids, pager.Offset)
+	}
+
+	// Resuming after stopping in the middle of a page gives the rest of the page (and the items already given of it)
+	// rather than skipping it.
+	pager = &OffsetPager{Limit: 2}
+	it = NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "123/comments", "abc", nil), pager)
+	it.MaxItems = 3
+	ids = ""
+	for it.Next() {
+		ids += it.Item().ID
+	}
+	if ids != "012" || pager.Offset != 2 {
+		t.Errorf("got IDs %q and offset %d with MaxItems", ids, pager.Offset)
+	}
+	it = NewIter[UserPage](context.Background(), c, c.Req(http.MethodGet, "123/comments", "abc", nil), pager)
+	ids = ""
+	for it.Next() {
+		ids += it.Item().ID
+	}
+	if ids != "2345" || pager.Offset != 6 {
+		t.Errorf("got IDs %q and offset %d resuming", ids, pager.Offset)
+	}
+}
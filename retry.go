@@ -0,0 +1,151 @@
+package fb
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// CodeTemporarilyBlocked is the error code Facebook gives when the app or user is temporarily blocked from making
+// some calls, such as for making too many of them too quickly.
+const CodeTemporarilyBlocked = 368
+
+// A RetryPolicy says how a Client retries requests that fail with network errors, 5xx status codes, or Graph API
+// errors that are transient or due to rate limiting. The delay between attempts grows exponentially with random
+// jitter, but a request is retried no sooner than Facebook estimates that access will be regained.
+//
+// Requests with a body are retried only if they have GetBody set, which Req does for POST requests. A network error
+// may happen after Facebook has acted on a request, so only GET, HEAD, and DELETE requests are retried after network
+// errors unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	MaxAttempts int           // The maximum number of attempts, including the first; if zero, 3 is used.
+	BaseDelay   time.Duration // The delay before the first retry; if zero, 500 milliseconds is used.
+
+	// MaxDelay is the longest delay before a retry; if zero, 30 seconds is used. If Facebook says that access will
+	// be regained only after a longer time, the request is not retried.
+	MaxDelay time.Duration
+
+	// RetryNonIdempotent makes requests with other methods, such as POST, be retried after network errors too, which
+	// may make Facebook act on them more than once.
+	RetryNonIdempotent bool
+}
+
+func (rp *RetryPolicy) maxAttempts() int {
+	if rp.MaxAttempts > 0 {
+		return rp.MaxAttempts
+	}
+	return 3
+}
+
+func (rp *RetryPolicy) baseDelay() time.Duration {
+	if rp.BaseDelay > 0 {
+		return rp.BaseDelay
+	}
+	return 500 * time.Millisecond
+}
+
+func (rp *RetryPolicy) maxDelay() time.Duration {
+	if rp.MaxDelay > 0 {
+		return rp.MaxDelay
+	}
+	return 30 * time.Second
+}
+
+// backoff gives the delay before the given retry (starting at 1) with jitter between half and all of the
+// exponentially growing delay.
+func (rp *RetryPolicy) backoff(retry int) time.Duration {
+	d := rp.baseDelay()
+	for i := 1; i < retry && d < rp.maxDelay(); i++ {
+		d *= 2
+	}
+	if d > rp.maxDelay() {
+		d = rp.maxDelay()
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryableErrResponse says if the error given by Facebook may go away when the request is retried.
+func retryableErrResponse(er *ErrResponse) bool {
+	return er.transient() || er.rateLimited() || er.Code == CodeTemporarilyBlocked
+}
+
+// check says whether the result of an attempt of the request should be retried and, if so, the minimum delay before
+// the retry. If the response status is not 2xx, the body is read and replaced so that it can still be read by the
+// caller.
+func (rp *RetryPolicy) check(req *http.Request, resp *http.Response, err error) (bool, time.Duration, error) {
+	if err != nil {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			return true, 0, nil
+		}
+		return rp.RetryNonIdempotent, 0, nil
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, 0, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return false, 0, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	var er *ErrResponse
+	if !errors.As(decodeBody(resp.StatusCode, resp.Header, body, nil), &er) {
+		return false, 0, nil
+	}
+	var regain time.Duration
+	if er.Usage != nil {
+		regain = er.Usage.RegainAccessIn()
+	}
+	if regain > rp.maxDelay() {
+		return false, 0, nil
+	}
+	return resp.StatusCode >= 500 || retryableErrResponse(er), regain, nil
+}
+
+// do runs the request with c.do as many times as the policy allows.
+func (rp *RetryPolicy) do(c *Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		resp, err := c.do(r)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		retry, regain, checkErr := rp.check(req, resp, err)
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !retry || !canReplay || attempt >= rp.maxAttempts() {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		delay := rp.backoff(attempt)
+		if regain > delay {
+			delay = regain
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
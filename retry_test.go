@@ -0,0 +1,130 @@
+package fb
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.PostForm.Get("access_token") != "abc" {
+			t.Errorf("attempt %d: the body was not replayed", attempts)
+		}
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"message":"An unexpected error has occurred.","code":2,"is_transient":true}}`))
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"(#4) Application request limit reached","code":4}}`))
+		default:
+			w.Write([]byte(`{"success":true}`))
+		}
+	})
+
+	c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+	resp, err := c.ReqDo(http.MethodPost, "123/subscribed_apps", "abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := new(SubscribeAppResponse)
+	if err := c.DecodeResponse(resp, v); err != nil || !v.Success {
+		t.Errorf("got %+v and error %v", *v, err)
+	}
+	if attempts != 3 {
+		t.Errorf("got %d attempts", attempts)
+	}
+}
+
+func TestRetryPolicy_NotRetryable(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-Business-Use-Case-Usage",
+			`{"123":[{"type":"pages","call_count":100,"estimated_time_to_regain_access":60}]}`)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"(#80001) There have been too many calls to this Page account.","code":80001}}`))
+	})
+
+	c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+	resp, err := c.ReqDo(http.MethodGet, "123", "abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DecodeResponse(resp, nil); !IsRateLimited(err) {
+		t.Errorf("got error %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts when access is regained only in an hour", attempts)
+	}
+}
+
+func TestClient_NextPage_Retry(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Query().Get("appsecret_proof") == "" {
+			t.Error("the appsecret_proof was not added")
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"message":"An unexpected error has occurred.","code":2,"is_transient":true}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	c.AppSecret = "secret"
+	c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+	resp, err := c.NextPage(c.BaseURL.String() + "/v2.12/me/accounts?access_token=abc&after=c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("got status %d after %d attempts", resp.StatusCode, attempts)
+	}
+}
+
+func TestRetryPolicy_NetworkError(t *testing.T) {
+	var attempts int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	})
+
+	c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+	cases := []struct {
+		Method             string
+		RetryNonIdempotent bool
+		Attempts           int32
+	}{
+		{http.MethodGet, false, 3},
+		{http.MethodDelete, false, 3},
+		{http.MethodPost, false, 1},
+		{http.MethodPost, true, 3},
+	}
+	for _, tc := range cases {
+		atomic.StoreInt32(&attempts, 0)
+		c.Retry.RetryNonIdempotent = tc.RetryNonIdempotent
+		if _, err := c.ReqDo(tc.Method, "123/subscribed_apps", "abc", nil); err == nil {
+			t.Errorf("%s: expected a network error", tc.Method)
+		}
+		if n := atomic.LoadInt32(&attempts); n != tc.Attempts {
+			t.Errorf("%s (RetryNonIdempotent %v): got %d attempts; expected %d", tc.Method, tc.RetryNonIdempotent, n, tc.Attempts)
+		}
+	}
+}
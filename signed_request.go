@@ -0,0 +1,76 @@
+package fb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrSignedRequest is returned by ParseSignedRequest if the signed request is malformed or its signature is wrong.
+var ErrSignedRequest = errors.New("fb: invalid signed_request")
+
+// A SignedRequest is the decoded payload of the signed_request parameter that Facebook gives to canvas apps, page
+// tabs, deauthorize callbacks, and data deletion callbacks. Which fields are set depends on the context.
+// Info: https://developers.facebook.com/docs/reference/login/signed-request
+type SignedRequest struct {
+	Algorithm  string `json:"algorithm"`
+	IssuedAt   int64  `json:"issued_at"` // A Unix timestamp.
+	Expires    int64  `json:"expires"`   // When OAuthToken expires, as a Unix timestamp.
+	UserID     string `json:"user_id"`   // An app-scoped user ID.
+	OAuthToken string `json:"oauth_token"`
+	Code       string `json:"code"`
+	AppData    string `json:"app_data"`
+	Page       *struct {
+		ID    string `json:"id"`
+		Liked bool   `json:"liked"`
+		Admin bool   `json:"admin"`
+	} `json:"page"`
+	User *struct {
+		Country string `json:"country"`
+		Locale  string `json:"locale"`
+		Age     struct {
+			Min int `json:"min"`
+			Max int `json:"max"`
+		} `json:"age"`
+	} `json:"user"`
+}
+
+// ParseSignedRequest verifies the signature of a signed request with the app secret and decodes the payload. The raw
+// value is made of the base64url-encoded HMAC-SHA256 signature and the base64url-encoded JSON payload, separated by a
+// period. The signature is compared in constant time, and the algorithm given in the payload must be HMAC-SHA256.
+func ParseSignedRequest(appSecret, raw string) (*SignedRequest, error) {
+	i := strings.IndexByte(raw, '.')
+	if i < 0 || appSecret == "" {
+		return nil, ErrSignedRequest
+	}
+	encodedSig, encodedPayload := raw[:i], raw[i+1:]
+	sig, err := decodeBase64URL(encodedSig)
+	if err != nil {
+		return nil, ErrSignedRequest
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(encodedPayload))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrSignedRequest
+	}
+	payload, err := decodeBase64URL(encodedPayload)
+	if err != nil {
+		return nil, ErrSignedRequest
+	}
+	sr := new(SignedRequest)
+	if err := json.Unmarshal(payload, sr); err != nil {
+		return nil, ErrSignedRequest
+	}
+	if !strings.EqualFold(sr.Algorithm, "HMAC-SHA256") {
+		return nil, ErrSignedRequest
+	}
+	return sr, nil
+}
+
+// decodeBase64URL decodes base64url with or without padding.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,45 @@
+package fb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+// signRequest makes a signed request the way Facebook does.
+func signRequest(appSecret, payload string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + encoded
+}
+
+func TestParseSignedRequest(t *testing.T) {
+	raw := signRequest("secret", `{"algorithm":"HMAC-SHA256","issued_at":1522862162,"user_id":"218471",`+
+		`"oauth_token":"token","expires":1522869362,"page":{"id":"123","admin":true},"user":{"country":"us","age":{"min":21}}}`)
+	sr, err := ParseSignedRequest("secret", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.UserID != "218471" || sr.IssuedAt != 1522862162 || sr.OAuthToken != "token" || sr.Expires != 1522869362 {
+		t.Errorf("got signed request %+v", *sr)
+	}
+	if sr.Page == nil || sr.Page.ID != "123" || !sr.Page.Admin || sr.User == nil || sr.User.Age.Min != 21 {
+		t.Errorf("got page %+v and user %+v", sr.Page, sr.User)
+	}
+
+	invalid := []string{
+		raw[:len(raw)-2],
+		"x" + raw,
+		"no-period",
+		signRequest("other", `{"algorithm":"HMAC-SHA256","user_id":"218471"}`),
+		signRequest("secret", `{"algorithm":"HMAC-SHA1","user_id":"218471"}`),
+		signRequest("secret", `not json`),
+	}
+	for i, raw := range invalid {
+		if _, err := ParseSignedRequest("secret", raw); err != ErrSignedRequest {
+			t.Errorf("case %d: got error %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A TokenKind is the kind of entity an access token belongs to.
+type TokenKind string
+
+// The kinds of tokens kept in a TokenStore.
+const (
+	TokenKindUser       TokenKind = "user"
+	TokenKindPage       TokenKind = "page"
+	TokenKindSystemUser TokenKind = "system_user"
+)
+
+// A TokenKey identifies a token in a TokenStore by the kind and ID of the entity it belongs to.
+type TokenKey struct {
+	Kind TokenKind
+	ID   string
+}
+
+// A StoredToken is an access token with what is known about its lifetime.
+type StoredToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`        // The zero time if the token does not expire.
+	Invalid     bool      `json:"invalid,omitempty"` // Set if Facebook reported the token as no longer valid.
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ErrTokenNotFound is returned by a TokenStore for an unknown key.
+var ErrTokenNotFound = errors.New("fb: token not found")
+
+// A TokenStore persists access tokens.
+type TokenStore interface {
+	// Token gives the token with the key, or ErrTokenNotFound.
+	Token(ctx context.Context, key TokenKey) (*StoredToken, error)
+	PutToken(ctx context.Context, key TokenKey, t *StoredToken) error
+	DeleteToken(ctx context.Context, key TokenKey) error
+
+	// Tokens lists the keys of the tokens of the given kind.
+	Tokens(ctx context.Context, kind TokenKind) ([]TokenKey, error)
+}
+
+// tokenMap is the content of the memory and file token stores.
+type tokenMap map[TokenKind]map[string]StoredToken
+
+func (m tokenMap) get(key TokenKey) (*StoredToken, error) {
+	t, ok := m[key.Kind][key.ID]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &t, nil
+}
+
+func (m tokenMap) put(key TokenKey, t *StoredToken) {
+	if m[key.Kind] == nil {
+		m[key.Kind] = make(map[string]StoredToken)
+	}
+	m[key.Kind][key.ID] = *t
+}
+
+func (m tokenMap) keys(kind TokenKind) []TokenKey {
+	keys := make([]TokenKey, 0, len(m[kind]))
+	for id := range m[kind] {
+		keys = append(keys, TokenKey{kind, id})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// A MemoryTokenStore is a TokenStore that keeps the tokens in memory. The zero value is ready to use.
+type MemoryTokenStore struct {
+	mu sync.Mutex
+	m  tokenMap
+}
+
+// Token implements TokenStore.
+func (s *MemoryTokenStore) Token(_ context.Context, key TokenKey) (*StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.get(key)
+}
+
+// PutToken implements TokenStore.
+func (s *MemoryTokenStore) PutToken(_ context.Context, key TokenKey, t *StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(tokenMap)
+	}
+	s.m.put(key, t)
+	return nil
+}
+
+// DeleteToken implements TokenStore.
+func (s *MemoryTokenStore) DeleteToken(_ context.Context, key TokenKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m[key.Kind], key.ID)
+	return nil
+}
+
+// Tokens implements TokenStore.
+func (s *MemoryTokenStore) Tokens(_ context.Context, kind TokenKind) ([]TokenKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.keys(kind), nil
+}
+
+// A FileTokenStore is a TokenStore that keeps the tokens in a JSON file, which is read for each operation and
+// replaced atomically for each change. It is safe for use by multiple goroutines but not by multiple processes. The
+// file contains secrets, so it is created with permissions 0600.
+type FileTokenStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// load reads the file; a missing file is an empty store.
+func (s *FileTokenStore) load() (tokenMap, error) {
+	m := make(tokenMap)
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// save writes the tokens to a temporary file that is renamed to the path.
+func (s *FileTokenStore) save(m tokenMap) error {
+	b, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.Path)
+}
+
+// Token implements TokenStore.
+func (s *FileTokenStore) Token(_ context.Context, key TokenKey) (*StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return m.get(key)
+}
+
+// PutToken implements TokenStore.
+func (s *FileTokenStore) PutToken(_ context.Context, key TokenKey, t *StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.load()
+	if err != nil {
+		return err
+	}
+	m.put(key, t)
+	return s.save(m)
+}
+
+// DeleteToken implements TokenStore.
+func (s *FileTokenStore) DeleteToken(_ context.Context, key TokenKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[key.Kind][key.ID]; !ok {
+		return nil
+	}
+	delete(m[key.Kind], key.ID)
+	return s.save(m)
+}
+
+// Tokens implements TokenStore.
+func (s *FileTokenStore) Tokens(_ context.Context, kind TokenKind) ([]TokenKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return m.keys(kind), nil
+}
+
+// A TokenRefresher keeps the tokens in a TokenStore usable. For each token not already flagged as invalid, it checks
+// the token with the debug_token endpoint and records its expiry. User tokens that expire within RefreshBefore are
+// exchanged for new long-lived tokens. Tokens that Facebook reports as no longer valid (for example because the user
+// changed their password or removed the app) are flagged as Invalid, and OnInvalid is called so that the user can be
+// asked to log in again.
+type TokenRefresher struct {
+	Client    *Client // If nil, DefaultClient is used.
+	Store     TokenStore
+	AppID     string
+	AppSecret string
+
+	// AppAccessToken is used to debug the tokens; if empty, the app token made of the AppID and AppSecret is used.
+	AppAccessToken string
+
+	// RefreshBefore is how long before it expires a user token is extended; if zero, 10 days is used.
+	RefreshBefore time.Duration
+
+	// OnInvalid, if set, is called for each token found to be invalid.
+	OnInvalid func(ctx context.Context, key TokenKey, t *StoredToken)
+
+	// OnError, if set, is called by Run with the errors of RefreshAll.
+	OnError func(err error)
+}
+
+// RefreshAll checks every token in the store once. It returns the first error encountered, but it continues with
+// the other tokens after an error.
+func (tr *TokenRefresher) RefreshAll(ctx context.Context) error {
+	var firstErr error
+	for _, kind := range []TokenKind{TokenKindUser, TokenKindPage, TokenKindSystemUser} {
+		keys, err := tr.Store.Tokens(ctx, kind)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := tr.Refresh(ctx, key); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Refresh checks the token with the key, extending it if it is a user token that expires soon.
+func (tr *TokenRefresher) Refresh(ctx context.Context, key TokenKey) error {
+	t, err := tr.Store.Token(ctx, key)
+	if err != nil {
+		return err
+	}
+	if t.Invalid {
+		return nil
+	}
+	c := tr.Client
+	if c == nil {
+		c = DefaultClient
+	}
+	appToken := tr.AppAccessToken
+	if appToken == "" {
+		appToken = tr.AppID + "|" + tr.AppSecret
+	}
+	debug, err := c.DebugTokenContext(ctx, appToken, t.AccessToken)
+	if err != nil {
+		return err
+	}
+	if debug.Error != nil {
+		return debug.Error
+	}
+	if !debug.Data.IsValid {
+		return tr.invalidate(ctx, key, t)
+	}
+	t.ExpiresAt = time.Time{}
+	if debug.Data.ExpiresAt > 0 {
+		t.ExpiresAt = time.Unix(debug.Data.ExpiresAt, 0)
+	}
+	t.UpdatedAt = time.Now()
+
+	refreshBefore := tr.RefreshBefore
+	if refreshBefore <= 0 {
+		refreshBefore = 10 * 24 * time.Hour
+	}
+	if key.Kind == TokenKindUser && !t.ExpiresAt.IsZero() && time.Until(t.ExpiresAt) < refreshBefore {
+		resp, err := c.DoContext(ctx, c.Rebase(ExtendedUserAccessTokenReq(t.AccessToken, tr.AppID, tr.AppSecret)))
+		if err != nil {
+			return err
+		}
+		extended := new(TokenResponse)
+		if err := c.DecodeResponse(resp, extended); err != nil {
+			if IsTokenInvalid(err) {
+				return tr.invalidate(ctx, key, t)
+			}
+			return err
+		}
+		if extended.AccessToken == "" {
+			return errors.New("fb: no access token in the response to the token extension")
+		}
+		t.AccessToken = extended.AccessToken
+		t.ExpiresAt = time.Time{}
+		if extended.ExpiresIn > 0 {
+			t.ExpiresAt = t.UpdatedAt.Add(time.Duration(extended.ExpiresIn) * time.Second)
+		}
+	}
+	return tr.Store.PutToken(ctx, key, t)
+}
+
+// invalidate flags the token as invalid.
+func (tr *TokenRefresher) invalidate(ctx context.Context, key TokenKey, t *StoredToken) error {
+	t.Invalid = true
+	t.UpdatedAt = time.Now()
+	if err := tr.Store.PutToken(ctx, key, t); err != nil {
+		return err
+	}
+	if tr.OnInvalid != nil {
+		tr.OnInvalid(ctx, key, t)
+	}
+	return nil
+}
+
+// Run calls RefreshAll right away and then at each interval until the context is done, returning the error of the
+// context. If interval is not positive, 1 hour is used.
+func (tr *TokenRefresher) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := tr.RefreshAll(ctx); err != nil && ctx.Err() == nil && tr.OnError != nil {
+			tr.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package fb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	s := &FileTokenStore{Path: filepath.Join(dir, "tokens.json")}
+	if _, err := s.Token(ctx, TokenKey{TokenKindUser, "u1"}); err != ErrTokenNotFound {
+		t.Errorf("got error %v for an empty store", err)
+	}
+	expires := time.Unix(1522862162, 0).UTC()
+	if err := s.PutToken(ctx, TokenKey{TokenKindUser, "u1"}, &StoredToken{AccessToken: "abc", ExpiresAt: expires}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutToken(ctx, TokenKey{TokenKindPage, "p1"}, &StoredToken{AccessToken: "def"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := &FileTokenStore{Path: s.Path}
+	tok, err := reopened.Token(ctx, TokenKey{TokenKindUser, "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "abc" || !tok.ExpiresAt.Equal(expires) {
+		t.Errorf("got token %+v", *tok)
+	}
+	keys, err := reopened.Tokens(ctx, TokenKindPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != (TokenKey{TokenKindPage, "p1"}) {
+		t.Errorf("got keys %v", keys)
+	}
+	if err := reopened.DeleteToken(ctx, TokenKey{TokenKindPage, "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Token(ctx, TokenKey{TokenKindPage, "p1"}); err != ErrTokenNotFound {
+		t.Errorf("got error %v for a deleted token", err)
+	}
+}
+
+func TestTokenRefresher(t *testing.T) {
+	soon := time.Now().Add(24 * time.Hour).Unix()
+	later := time.Now().Add(50 * 24 * time.Hour).Unix()
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/" + DefaultVersion + "/debug_token":
+			if q.Get("access_token") != "app|secret" {
+				t.Errorf("got app token %q", q.Get("access_token"))
+			}
+			switch q.Get("input_token") {
+			case "expiring", "unextendable":
+				w.Write([]byte(`{"data":{"is_valid":true,"expires_at":` + strconv.FormatInt(soon, 10) + `}}`))
+			case "fresh", "extended":
+				w.Write([]byte(`{"data":{"is_valid":true,"expires_at":` + strconv.FormatInt(later, 10) + `}}`))
+			case "page":
+				w.Write([]byte(`{"data":{"is_valid":true,"expires_at":0}}`))
+			default:
+				w.Write([]byte(`{"data":{"is_valid":false}}`))
+			}
+		case "/" + DefaultVersion + "/oauth/access_token":
+			if q.Get("client_secret") != "secret" {
+				t.Errorf("got exchange query %v", q)
+			}
+			switch q.Get("fb_exchange_token") {
+			case "expiring":
+				w.Write([]byte(`{"access_token":"extended","token_type":"bearer","expires_in":5183944}`))
+			case "unextendable":
+				w.Write([]byte(`{"token_type":"bearer"}`))
+			default:
+				t.Errorf("got exchange query %v", q)
+			}
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	ctx := context.Background()
+	store := new(MemoryTokenStore)
+	store.PutToken(ctx, TokenKey{TokenKindUser, "u1"}, &StoredToken{AccessToken: "expiring"})
+	store.PutToken(ctx, TokenKey{TokenKindUser, "u2"}, &StoredToken{AccessToken: "fresh"})
+	store.PutToken(ctx, TokenKey{TokenKindUser, "u3"}, &StoredToken{AccessToken: "revoked"})
+	store.PutToken(ctx, TokenKey{TokenKindPage, "p1"}, &StoredToken{AccessToken: "page"})
+
+	var invalid []TokenKey
+	tr := &TokenRefresher{
+		Client:    c,
+		Store:     store,
+		AppID:     "app",
+		AppSecret: "secret",
+		OnInvalid: func(_ context.Context, key TokenKey, _ *StoredToken) {
+			invalid = append(invalid, key)
+		},
+	}
+	if err := tr.RefreshAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tok, _ := store.Token(ctx, TokenKey{TokenKindUser, "u1"})
+	if tok.AccessToken != "extended" || time.Until(tok.ExpiresAt) < 59*24*time.Hour {
+		t.Errorf("got expiring token %+v", *tok)
+	}
+	tok, _ = store.Token(ctx, TokenKey{TokenKindUser, "u2"})
+	if tok.AccessToken != "fresh" || tok.ExpiresAt.Unix() != later {
+		t.Errorf("got fresh token %+v", *tok)
+	}
+	tok, _ = store.Token(ctx, TokenKey{TokenKindUser, "u3"})
+	if !tok.Invalid {
+		t.Errorf("revoked token not flagged")
+	}
+	tok, _ = store.Token(ctx, TokenKey{TokenKindPage, "p1"})
+	if tok.Invalid || !tok.ExpiresAt.IsZero() {
+		t.Errorf("got page token %+v", *tok)
+	}
+	if len(invalid) != 1 || invalid[0] != (TokenKey{TokenKindUser, "u3"}) {
+		t.Errorf("got invalid tokens %v", invalid)
+	}
+
+	// Tokens already flagged as invalid are skipped.
+	invalid = nil
+	if err := tr.RefreshAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(invalid) != 0 {
+		t.Errorf("got invalid tokens %v on the second run", invalid)
+	}
+
+	// A token is not replaced by an empty one.
+	key := TokenKey{TokenKindUser, "u4"}
+	store.PutToken(ctx, key, &StoredToken{AccessToken: "unextendable"})
+	if err := tr.Refresh(ctx, key); err == nil {
+		t.Error("expected an error for an extension without an access token")
+	}
+	if tok, _ = store.Token(ctx, key); tok.AccessToken != "unextendable" {
+		t.Errorf("got token %+v after a failed extension", *tok)
+	}
+
+	// Run does not panic without an interval.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := tr.Run(canceled, 0); err != context.Canceled {
+		t.Errorf("got error %v from Run", err)
+	}
+}
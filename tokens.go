@@ -1,6 +1,7 @@
 package fb
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -56,10 +57,15 @@ type TokenDebug struct {
 // DebugToken sends a token debug request to Facebook and reads the response.
 // If the client given is nil, then http.DefaultClient is used.
 func DebugToken(accessToken, tokenToDebug string, client *http.Client) (*TokenDebug, error) {
+	return DebugTokenContext(context.Background(), accessToken, tokenToDebug, client)
+}
+
+// DebugTokenContext is like DebugToken but sends the request with the given context.
+func DebugTokenContext(ctx context.Context, accessToken, tokenToDebug string, client *http.Client) (*TokenDebug, error) {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	resp, err := client.Do(DebugTokenReq(accessToken, tokenToDebug))
+	resp, err := client.Do(DebugTokenReq(accessToken, tokenToDebug).WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
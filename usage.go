@@ -0,0 +1,336 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// An AppUsage gives the percentages of the rate limits used, as reported in the X-App-Usage header for the app and
+// in the X-Page-Usage header for a page.
+// Info: https://developers.facebook.com/docs/graph-api/overview/rate-limiting
+type AppUsage struct {
+	CallCount    float64 `json:"call_count"`
+	TotalCPUTime float64 `json:"total_cputime"`
+	TotalTime    float64 `json:"total_time"`
+
+	// The number of minutes until calls will no longer be throttled; zero if not throttled. Given for pages only.
+	EstimatedTimeToRegainAccess int `json:"estimated_time_to_regain_access"`
+}
+
+// Percent gives the highest of the percentages.
+func (au *AppUsage) Percent() float64 {
+	return maxFloat(au.CallCount, au.TotalCPUTime, au.TotalTime)
+}
+
+// An AdAccountUsage gives the usage of the rate limit for an ad account, as reported in the X-Ad-Account-Usage header.
+type AdAccountUsage struct {
+	AccIDUtilPct       float64 `json:"acc_id_util_pct"`
+	ResetTimeInSeconds int     `json:"reset_time_in_seconds"`
+	AdsAPIAccessTier   string  `json:"ads_api_access_tier"`
+}
+
+// A BusinessUseCaseUsage gives the usage of the rate limit for one type of business use case (such as "pages" or
+// "ads_management"), as reported in the X-Business-Use-Case-Usage header.
+type BusinessUseCaseUsage struct {
+	Type         string  `json:"type"`
+	CallCount    float64 `json:"call_count"`
+	TotalCPUTime float64 `json:"total_cputime"`
+	TotalTime    float64 `json:"total_time"`
+
+	// The number of minutes until calls will no longer be throttled; zero if not throttled.
+	EstimatedTimeToRegainAccess int `json:"estimated_time_to_regain_access"`
+}
+
+// Percent gives the highest of the percentages.
+func (bu *BusinessUseCaseUsage) Percent() float64 {
+	return maxFloat(bu.CallCount, bu.TotalCPUTime, bu.TotalTime)
+}
+
+// A Usage collects the rate limit usage reported in the headers of a response. Each field is nil if the
+// corresponding header was not given.
+type Usage struct {
+	App             *AppUsage
+	Page            *AppUsage
+	AdAccount       *AdAccountUsage
+	BusinessUseCase map[string][]BusinessUseCaseUsage // Keyed by business ID.
+}
+
+// ParseUsage decodes the rate limit usage headers, which can be taken from an http.Response or a BatchResponse.
+// The returned Usage is nil if none of the headers is given.
+func ParseUsage(h http.Header) (*Usage, error) {
+	u := new(Usage)
+	found := false
+	for _, hv := range []struct {
+		name string
+		v    interface{}
+	}{
+		{"X-App-Usage", &u.App},
+		{"X-Page-Usage", &u.Page},
+		{"X-Ad-Account-Usage", &u.AdAccount},
+		{"X-Business-Use-Case-Usage", &u.BusinessUseCase},
+	} {
+		val := h.Get(hv.name)
+		if val == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(val), hv.v); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return u, nil
+}
+
+// Percent gives the highest percentage of any rate limit in the usage.
+func (u *Usage) Percent() float64 {
+	var pct float64
+	if u.App != nil {
+		pct = maxFloat(pct, u.App.Percent())
+	}
+	if u.Page != nil {
+		pct = maxFloat(pct, u.Page.Percent())
+	}
+	if u.AdAccount != nil {
+		pct = maxFloat(pct, u.AdAccount.AccIDUtilPct)
+	}
+	for _, buc := range u.BusinessUseCase {
+		for i := range buc {
+			pct = maxFloat(pct, buc[i].Percent())
+		}
+	}
+	return pct
+}
+
+// RegainAccessIn gives the longest time until calls will no longer be throttled; zero if not throttled.
+func (u *Usage) RegainAccessIn() time.Duration {
+	var minutes int
+	if u.Page != nil && u.Page.EstimatedTimeToRegainAccess > minutes {
+		minutes = u.Page.EstimatedTimeToRegainAccess
+	}
+	for _, buc := range u.BusinessUseCase {
+		for i := range buc {
+			if buc[i].EstimatedTimeToRegainAccess > minutes {
+				minutes = buc[i].EstimatedTimeToRegainAccess
+			}
+		}
+	}
+	d := time.Duration(minutes) * time.Minute
+	if u.AdAccount != nil && u.AdAccount.AccIDUtilPct >= 100 {
+		if reset := time.Duration(u.AdAccount.ResetTimeInSeconds) * time.Second; reset > d {
+			d = reset
+		}
+	}
+	return d
+}
+
+func maxFloat(f float64, fs ...float64) float64 {
+	for _, v := range fs {
+		if v > f {
+			f = v
+		}
+	}
+	return f
+}
+
+// A Throttler slows down the requests made by a Client as the usage reported by Facebook approaches the rate limits.
+// Usage is tracked separately for the app, for each business or page ID given in the X-Business-Use-Case-Usage
+// header, and for each access token, which the X-Page-Usage and X-Ad-Account-Usage headers are about since they do
+// not name a node. A request is delayed according to the usage of the app, of its access token, and of the IDs last
+// reported for requests with its access token, so that a throttled page does not slow down requests for other pages
+// even when the requests are for nodes such as leads and forms. A Throttler must not be copied after first use.
+type Throttler struct {
+	// SlowAt is the usage percentage at which requests begin to be delayed; if zero, 75 is used.
+	SlowAt float64
+
+	// MaxDelay is the delay given to requests as the usage approaches 100 percent; if zero, 10 seconds is used.
+	// When the usage reaches 100 percent, requests are paused until Facebook says access is regained, or for
+	// MaxDelay if Facebook gives no estimate.
+	MaxDelay time.Duration
+
+	// Expiry is how long a usage report is taken into account if no newer one is given; if zero, 5 minutes is used.
+	Expiry time.Duration
+
+	mu      sync.Mutex
+	states  map[string]throttleState
+	ids     map[string]throttleIDs // The business use case IDs last reported for each access token.
+	sweepAt time.Time              // When the expired entries are next deleted.
+}
+
+type throttleState struct {
+	pct      float64
+	pauseEnd time.Time // When a pause ends if the usage is at 100 percent.
+	expires  time.Time
+}
+
+type throttleIDs struct {
+	ids     []string
+	expires time.Time
+}
+
+const throttleAppKey = "app"
+
+func (t *Throttler) slowAt() float64 {
+	if t.SlowAt > 0 {
+		return t.SlowAt
+	}
+	return 75
+}
+
+func (t *Throttler) maxDelay() time.Duration {
+	if t.MaxDelay > 0 {
+		return t.MaxDelay
+	}
+	return 10 * time.Second
+}
+
+func (t *Throttler) expiry() time.Duration {
+	if t.Expiry > 0 {
+		return t.Expiry
+	}
+	return 5 * time.Minute
+}
+
+// Observe records the usage reported for a request made with the access token (which may be empty).
+func (t *Throttler) Observe(accessToken string, u *Usage) {
+	if u == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.states == nil {
+		t.states = make(map[string]throttleState)
+		t.ids = make(map[string]throttleIDs)
+	}
+	t.sweep(now)
+	if u.App != nil {
+		t.states[throttleAppKey] = t.state(now, u.App.Percent(), 0)
+	}
+	if accessToken == "" {
+		return
+	}
+	if u.Page != nil || u.AdAccount != nil {
+		tokenUsage := Usage{Page: u.Page, AdAccount: u.AdAccount}
+		t.states["token:"+accessToken] = t.state(now, tokenUsage.Percent(), tokenUsage.RegainAccessIn())
+	}
+	if u.BusinessUseCase != nil {
+		ids := make([]string, 0, len(u.BusinessUseCase))
+		for id, buc := range u.BusinessUseCase {
+			idUsage := Usage{BusinessUseCase: map[string][]BusinessUseCaseUsage{id: buc}}
+			t.states["id:"+id] = t.state(now, idUsage.Percent(), idUsage.RegainAccessIn())
+			ids = append(ids, id)
+		}
+		t.ids[accessToken] = throttleIDs{ids: ids, expires: now.Add(t.expiry())}
+	}
+}
+
+func (t *Throttler) state(now time.Time, pct float64, regain time.Duration) throttleState {
+	if regain == 0 {
+		regain = t.maxDelay()
+	}
+	return throttleState{pct: pct, pauseEnd: now.Add(regain), expires: now.Add(t.expiry())}
+}
+
+// sweep deletes the expired entries, at most once per expiry period; t.mu must be held.
+func (t *Throttler) sweep(now time.Time) {
+	if now.Before(t.sweepAt) {
+		return
+	}
+	for key, st := range t.states {
+		if now.After(st.expires) {
+			delete(t.states, key)
+		}
+	}
+	for token, ti := range t.ids {
+		if now.After(ti.expires) {
+			delete(t.ids, token)
+		}
+	}
+	t.sweepAt = now.Add(t.expiry())
+}
+
+// Delay gives how long a request made with the access token (which may be empty) should wait given the usage
+// observed.
+func (t *Throttler) Delay(accessToken string) time.Duration {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.states == nil {
+		return 0
+	}
+	t.sweep(now)
+	keys := []string{throttleAppKey}
+	if accessToken != "" {
+		keys = append(keys, "token:"+accessToken)
+		for _, id := range t.ids[accessToken].ids {
+			keys = append(keys, "id:"+id)
+		}
+	}
+	var d time.Duration
+	for _, key := range keys {
+		st, ok := t.states[key]
+		if !ok || now.After(st.expires) {
+			continue
+		}
+		var sd time.Duration
+		switch {
+		case st.pct >= 100:
+			sd = st.pauseEnd.Sub(now)
+		case st.pct >= t.slowAt():
+			sd = time.Duration(float64(t.maxDelay()) * (st.pct - t.slowAt()) / (100 - t.slowAt()))
+		}
+		if sd > d {
+			d = sd
+		}
+	}
+	return d
+}
+
+// Wait blocks for as long as a request made with the access token should be delayed, or until the context is done.
+func (t *Throttler) Wait(ctx context.Context, accessToken string) error {
+	d := t.Delay(accessToken)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// requestToken gives the access token a request is made with, taken from the URL or, for a POST request set up with
+// Req, from the form body.
+func requestToken(r *http.Request) string {
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token
+	}
+	if r.Method != http.MethodPost || r.GetBody == nil {
+		return ""
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return ""
+	}
+	b, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return ""
+	}
+	v, err := url.ParseQuery(string(b))
+	if err != nil {
+		return ""
+	}
+	return v.Get("access_token")
+}
@@ -0,0 +1,146 @@
+package fb
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseUsage(t *testing.T) {
+	h := make(http.Header)
+	if u, err := ParseUsage(h); u != nil || err != nil {
+		t.Errorf("got %v and error %v without headers", u, err)
+	}
+
+	h.Set("X-App-Usage", `{"call_count":28,"total_time":25,"total_cputime":25}`)
+	h.Set("X-Ad-Account-Usage", `{"acc_id_util_pct":9.67,"reset_time_in_seconds":0,"ads_api_access_tier":"standard_access"}`)
+	h.Set("X-Business-Use-Case-Usage", `{"112233":[{"type":"pages","call_count":100,"total_cputime":25,`+
+		`"total_time":25,"estimated_time_to_regain_access":19}]}`)
+	u, err := ParseUsage(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.App == nil || u.App.CallCount != 28 || u.Page != nil {
+		t.Errorf("got app usage %+v and page usage %+v", u.App, u.Page)
+	}
+	if u.AdAccount == nil || u.AdAccount.AdsAPIAccessTier != "standard_access" {
+		t.Errorf("got ad account usage %+v", u.AdAccount)
+	}
+	if buc := u.BusinessUseCase["112233"]; len(buc) != 1 || buc[0].Type != "pages" {
+		t.Errorf("got business use case usage %+v", u.BusinessUseCase)
+	}
+	if u.Percent() != 100 || u.RegainAccessIn() != 19*time.Minute {
+		t.Errorf("got percent %v and regain access in %v", u.Percent(), u.RegainAccessIn())
+	}
+
+	h.Set("X-Page-Usage", `{"call_count":`)
+	if _, err := ParseUsage(h); err == nil {
+		t.Error("expected an error for a malformed header")
+	}
+}
+
+func TestThrottler_Delay(t *testing.T) {
+	th := &Throttler{SlowAt: 50, MaxDelay: 10 * time.Second}
+	th.Observe("token1", &Usage{App: &AppUsage{CallCount: 40}, Page: &AppUsage{CallCount: 75}})
+	if d := th.Delay("token2"); d != 0 {
+		t.Errorf("got delay %v for another token", d)
+	}
+	if d := th.Delay("token1"); d != 5*time.Second {
+		t.Errorf("got delay %v for the token", d)
+	}
+
+	th.Observe("token1", &Usage{Page: &AppUsage{CallCount: 100, EstimatedTimeToRegainAccess: 2}})
+	if d := th.Delay("token1"); d < time.Minute || d > 2*time.Minute {
+		t.Errorf("got delay %v for a throttled page", d)
+	}
+
+	th.Observe("", &Usage{App: &AppUsage{TotalTime: 60}})
+	if d := th.Delay("token2"); d != 2*time.Second {
+		t.Errorf("got delay %v with app usage", d)
+	}
+}
+
+func TestThrottler_BusinessUseCase(t *testing.T) {
+	th := &Throttler{SlowAt: 50, MaxDelay: 10 * time.Second}
+	th.Observe("token1", &Usage{BusinessUseCase: map[string][]BusinessUseCaseUsage{
+		"page1": {{Type: "pages", CallCount: 100, EstimatedTimeToRegainAccess: 3}},
+	}})
+	th.Observe("token2", &Usage{BusinessUseCase: map[string][]BusinessUseCaseUsage{
+		"page2": {{Type: "pages", CallCount: 10}},
+	}})
+	if d := th.Delay("token1"); d < 2*time.Minute || d > 3*time.Minute {
+		t.Errorf("got delay %v for a token of a throttled page", d)
+	}
+	if d := th.Delay("token2"); d != 0 {
+		t.Errorf("got delay %v for a token of another page", d)
+	}
+
+	// Another token reported for the same page is throttled as well.
+	th.Observe("token3", &Usage{BusinessUseCase: map[string][]BusinessUseCaseUsage{
+		"page1": {{Type: "pages", CallCount: 100, EstimatedTimeToRegainAccess: 3}},
+	}})
+	if d := th.Delay("token3"); d < 2*time.Minute {
+		t.Errorf("got delay %v for another token of the throttled page", d)
+	}
+}
+
+func TestThrottler_Expiry(t *testing.T) {
+	th := &Throttler{Expiry: time.Millisecond}
+	for _, token := range []string{"token1", "token2", "token3"} {
+		th.Observe(token, &Usage{Page: &AppUsage{CallCount: 90}, BusinessUseCase: map[string][]BusinessUseCaseUsage{
+			token: {{Type: "pages", CallCount: 90}},
+		}})
+	}
+	time.Sleep(5 * time.Millisecond)
+	if d := th.Delay("token1"); d != 0 {
+		t.Errorf("got delay %v from an expired report", d)
+	}
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	if len(th.states) != 0 || len(th.ids) != 0 {
+		t.Errorf("got %d states and %d ID lists after they expired", len(th.states), len(th.ids))
+	}
+}
+
+func TestRequestToken(t *testing.T) {
+	c := &Client{}
+	if got := requestToken(c.Req(http.MethodGet, "123/leads", "abc", nil)); got != "abc" {
+		t.Errorf("got token %q for a GET request", got)
+	}
+	if got := requestToken(c.Req(http.MethodPost, "123/subscribed_apps", "abc", nil)); got != "abc" {
+		t.Errorf("got token %q for a POST request", got)
+	}
+	if got := requestToken(c.Req(http.MethodGet, "123", "", nil)); got != "" {
+		t.Errorf("got token %q for a request without a token", got)
+	}
+}
+
+func TestClient_Throttler(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+DefaultVersion+"/page1/leadgen_forms" {
+			w.Header().Set("X-Business-Use-Case-Usage", `{"page1":[{"type":"pages","call_count":100}]}`)
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	c.Throttler = &Throttler{MaxDelay: 50 * time.Millisecond}
+	resp, err := c.ReqDo(http.MethodGet, "page1/leadgen_forms", "page-token", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if d := c.Throttler.Delay("other-token"); d != 0 {
+		t.Errorf("got delay %v for another token", d)
+	}
+
+	// A request about a lead of the page is made with the page token, so it waits.
+	start := time.Now()
+	resp, err = c.ReqDo(http.MethodGet, "lead1", "page-token", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("got a request about a lead of the throttled page delayed for %v", d)
+	}
+}
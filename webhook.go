@@ -0,0 +1,129 @@
+package fb
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DefaultWebhookMaxBodySize is the largest webhook notification payload a WebhookHandler accepts by default.
+const DefaultWebhookMaxBodySize = 1 << 20
+
+// ErrWebhookSignature is returned by VerifyWebhookSignature if the signature is missing, malformed, or wrong.
+var ErrWebhookSignature = errors.New("fb: invalid webhook signature")
+
+// VerifyWebhookSignature checks the signature Facebook gives for a webhook notification payload, which is the HMAC of
+// the raw body keyed with the app secret. The X-Hub-Signature-256 header (SHA-256) is used if given; otherwise the
+// legacy X-Hub-Signature header (SHA-1) is used. The comparison is done in constant time.
+// Info: https://developers.facebook.com/docs/graph-api/webhooks/getting-started#event-notifications
+func VerifyWebhookSignature(appSecret string, body []byte, header http.Header) error {
+	if appSecret == "" {
+		return ErrWebhookSignature
+	}
+	var newHash func() hash.Hash
+	sig := header.Get("X-Hub-Signature-256")
+	if sig != "" {
+		newHash = sha256.New
+		sig = strings.TrimPrefix(sig, "sha256=")
+	} else if sig = header.Get("X-Hub-Signature"); sig != "" {
+		newHash = sha1.New
+		sig = strings.TrimPrefix(sig, "sha1=")
+	} else {
+		return ErrWebhookSignature
+	}
+	given, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+	mac := hmac.New(newHash, []byte(appSecret))
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
+
+// A WebhookHandler is an http.Handler for the callback URL of webhooks. It answers the verification request that
+// Facebook makes when the subscription is set up and, for each notification, checks the signature of the payload
+// and gives the decoded WebhookNotif to the Handle function.
+type WebhookHandler struct {
+	AppSecret   string // Used to verify the signature of notifications; must be set.
+	VerifyToken string // The token given when setting up the subscription.
+
+	// MaxBodySize is the largest payload accepted; if zero, DefaultWebhookMaxBodySize is used.
+	MaxBodySize int64
+
+	// Handle is called with each notification. If it returns an error, the handler responds with status 500 so
+	// that Facebook retries sending the notification later. The context is that of the HTTP request.
+	Handle func(ctx context.Context, n *WebhookNotif) error
+}
+
+// ServeHTTP implements http.Handler.
+func (wh *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		wh.verify(w, r)
+	case http.MethodPost:
+		wh.notify(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// verify answers the verification request.
+// Info: https://developers.facebook.com/docs/graph-api/webhooks/getting-started#verification-requests
+func (wh *WebhookHandler) verify(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	token := q.Get("hub.verify_token")
+	if q.Get("hub.mode") != "subscribe" || wh.VerifyToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(wh.VerifyToken)) != 1 {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, q.Get("hub.challenge"))
+}
+
+// notify reads and verifies a notification.
+func (wh *WebhookHandler) notify(w http.ResponseWriter, r *http.Request) {
+	maxSize := wh.MaxBodySize
+	if maxSize <= 0 {
+		maxSize = DefaultWebhookMaxBodySize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > maxSize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := VerifyWebhookSignature(wh.AppSecret, body, r.Header); err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	n := new(WebhookNotif)
+	if err := json.Unmarshal(body, n); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if wh.Handle != nil {
+		if err := wh.Handle(r.Context(), n); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,198 @@
+package fb
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Webhook objects, given in the "object" field of a WebhookNotif.
+const (
+	WebhookObjectPage        = "page"
+	WebhookObjectUser        = "user"
+	WebhookObjectPermissions = "permissions"
+	WebhookObjectInstagram   = "instagram"
+	WebhookObjectApplication = "application"
+)
+
+// A WebhookChangeHandler handles the change of a field given in an entry of a webhook notification.
+type WebhookChangeHandler func(ctx context.Context, entry *WebhookEntry, change *WebhookChange) error
+
+type webhookRoute struct {
+	object, field string
+}
+
+// A WebhookRouter dispatches the changes given in webhook notifications to the handlers registered for the object
+// and field of each change. The Dispatch method can be used as the Handle function of a WebhookHandler. Handlers
+// must be registered before the router is used.
+type WebhookRouter struct {
+	// Fallback is called for the changes that have no registered handler. If nil, such changes are ignored.
+	Fallback WebhookChangeHandler
+
+	routes    map[webhookRoute]WebhookChangeHandler
+	messaging MessagingEventHandler
+	standby   MessagingEventHandler
+}
+
+// Handle registers the handler for changes to the field of the object, replacing any handler registered before.
+func (wr *WebhookRouter) Handle(object, field string, h WebhookChangeHandler) {
+	if wr.routes == nil {
+		wr.routes = make(map[webhookRoute]WebhookChangeHandler)
+	}
+	wr.routes[webhookRoute{object, field}] = h
+}
+
+// HandleWebhookValue registers a handler that receives the value of each change decoded into the type V.
+func HandleWebhookValue[V any](wr *WebhookRouter, object, field string,
+	h func(ctx context.Context, entry *WebhookEntry, v *V) error) {
+	wr.Handle(object, field, func(ctx context.Context, entry *WebhookEntry, change *WebhookChange) error {
+		v := new(V)
+		if len(change.Value) > 0 {
+			if err := json.Unmarshal(change.Value, v); err != nil {
+				return err
+			}
+		}
+		return h(ctx, entry, v)
+	})
+}
+
+// Dispatch calls the handler registered for each change and messaging event in the notification, stopping at the
+// first error. For entries that list only the names of the changed fields (as for the "user" object), a change
+// without a value is given for each field.
+func (wr *WebhookRouter) Dispatch(ctx context.Context, n *WebhookNotif) error {
+	for i := range n.Entry {
+		entry := &n.Entry[i]
+		if err := dispatchMessaging(ctx, wr.messaging, entry, entry.Messaging); err != nil {
+			return err
+		}
+		if err := dispatchMessaging(ctx, wr.standby, entry, entry.Standby); err != nil {
+			return err
+		}
+		changes := entry.Changes
+		if len(changes) == 0 {
+			for _, f := range entry.ChangedFields {
+				changes = append(changes, WebhookChange{Field: f})
+			}
+		}
+		for j := range changes {
+			h := wr.routes[webhookRoute{n.Object, changes[j].Field}]
+			if h == nil {
+				h = wr.Fallback
+			}
+			if h == nil {
+				continue
+			}
+			if err := h(ctx, entry, &changes[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HandleLeadgen registers a handler for the "leadgen" field of the "page" object.
+func (wr *WebhookRouter) HandleLeadgen(h func(ctx context.Context, entry *WebhookEntry, v *LeadGenEntry) error) {
+	HandleWebhookValue(wr, WebhookObjectPage, "leadgen", h)
+}
+
+// HandleFeed registers a handler for the "feed" field of the "page" object.
+func (wr *WebhookRouter) HandleFeed(h func(ctx context.Context, entry *WebhookEntry, v *FeedValue) error) {
+	HandleWebhookValue(wr, WebhookObjectPage, "feed", h)
+}
+
+// HandleMention registers a handler for the "mention" field of the "page" object.
+func (wr *WebhookRouter) HandleMention(h func(ctx context.Context, entry *WebhookEntry, v *MentionValue) error) {
+	HandleWebhookValue(wr, WebhookObjectPage, "mention", h)
+}
+
+// HandleRatings registers a handler for the "ratings" field of the "page" object.
+func (wr *WebhookRouter) HandleRatings(h func(ctx context.Context, entry *WebhookEntry, v *RatingValue) error) {
+	HandleWebhookValue(wr, WebhookObjectPage, "ratings", h)
+}
+
+// HandleInstagramComments registers a handler for the "comments" field of the "instagram" object.
+func (wr *WebhookRouter) HandleInstagramComments(h func(ctx context.Context, entry *WebhookEntry,
+	v *InstagramCommentValue) error) {
+	HandleWebhookValue(wr, WebhookObjectInstagram, "comments", h)
+}
+
+// HandlePermission registers a handler for changes to the given permission, such as "email" or "leads_retrieval",
+// which are notified with the "permissions" object.
+func (wr *WebhookRouter) HandlePermission(permission string,
+	h func(ctx context.Context, entry *WebhookEntry, v *PermissionValue) error) {
+	HandleWebhookValue(wr, WebhookObjectPermissions, permission, h)
+}
+
+// A FeedValue is a Page webhook notification value for the field "feed", which is given when a post, comment,
+// reaction, or share is added to, edited on, or removed from the page feed.
+type FeedValue struct {
+	Item         string `json:"item"` // enum{album, comment, like, photo, post, reaction, share, status, video, ...}
+	Verb         string `json:"verb"` // enum{add, block, edit, edited, delete, follow, hide, mute, remove, ...}
+	PostID       string `json:"post_id"`
+	CommentID    string `json:"comment_id"`
+	ParentID     string `json:"parent_id"`
+	From         IDName `json:"from"`
+	Message      string `json:"message"`
+	Link         string `json:"link"`
+	Photo        string `json:"photo"`
+	VideoID      string `json:"video_id"`
+	ReactionType string `json:"reaction_type"`
+	Published    int    `json:"published"`
+	CreatedTime  int64  `json:"created_time"`
+}
+
+// A MentionValue is a Page webhook notification value for the field "mention", which is given when the page is
+// mentioned in a post or comment.
+type MentionValue struct {
+	Item       string `json:"item"` // enum{post, comment}
+	Verb       string `json:"verb"` // enum{add, edit, edited, delete, remove}
+	PostID     string `json:"post_id"`
+	CommentID  string `json:"comment_id"`
+	SenderID   string `json:"sender_id"`
+	SenderName string `json:"sender_name"`
+	Message    string `json:"message"`
+}
+
+// A RatingValue is a Page webhook notification value for the field "ratings", which is given when a rating or
+// recommendation of the page changes.
+type RatingValue struct {
+	Item               string `json:"item"` // enum{rating, comment}
+	Verb               string `json:"verb"` // enum{add, edit, edited, delete, remove}
+	ReviewerID         string `json:"reviewer_id"`
+	ReviewerName       string `json:"reviewer_name"`
+	ReviewText         string `json:"review_text"`
+	Rating             int    `json:"rating"`
+	RecommendationType string `json:"recommendation_type"` // enum{positive, negative}
+	OpenGraphStoryID   string `json:"open_graph_story_id"`
+	CommentID          string `json:"comment_id"`
+	Message            string `json:"message"`
+	CreatedTime        int64  `json:"created_time"`
+}
+
+// An InstagramCommentValue is an Instagram webhook notification value for the field "comments", which is given
+// when a comment is made on a media object of the Instagram account.
+type InstagramCommentValue struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	ParentID string `json:"parent_id"` // Set for replies.
+	From     struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Media struct {
+		ID               string `json:"id"`
+		MediaProductType string `json:"media_product_type"`
+	} `json:"media"`
+}
+
+// A PermissionValue is a webhook notification value for the "permissions" object, which is given when a user grants
+// or revokes a permission. The field of the change is the name of the permission.
+type PermissionValue struct {
+	Verb      string   `json:"verb"`       // enum{granted, revoked}
+	TargetIDs []string `json:"target_ids"` // For granular permissions, the IDs of the objects concerned.
+}
+
+// An IDName identifies a user or page by ID and name.
+type IDName struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
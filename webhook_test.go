@@ -0,0 +1,131 @@
+package fb
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler_Verify(t *testing.T) {
+	wh := &WebhookHandler{VerifyToken: "token"}
+	cases := []struct {
+		Query  string
+		Status int
+		Body   string
+	}{
+		{"hub.mode=subscribe&hub.verify_token=token&hub.challenge=1158201444", http.StatusOK, "1158201444"},
+		{"hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1158201444", http.StatusForbidden, ""},
+		{"hub.mode=other&hub.verify_token=token&hub.challenge=1158201444", http.StatusForbidden, ""},
+	}
+	for i, tc := range cases {
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook?"+tc.Query, nil))
+		if rec.Code != tc.Status {
+			t.Errorf("case %d: got status %d", i, rec.Code)
+		}
+		if tc.Body != "" && rec.Body.String() != tc.Body {
+			t.Errorf("case %d: got body %q", i, rec.Body.String())
+		}
+	}
+}
+
+func TestWebhookHandler_Notify(t *testing.T) {
+	const payload = `{"object":"page","entry":[{"id":"123","time":1522862162,` +
+		`"changes":[{"field":"leadgen","value":{"leadgen_id":"444"}}]}]}`
+	sign256 := hmac.New(sha256.New, []byte("secret"))
+	sign256.Write([]byte(payload))
+	sign1 := hmac.New(sha1.New, []byte("secret"))
+	sign1.Write([]byte(payload))
+
+	var got *WebhookNotif
+	wh := &WebhookHandler{
+		AppSecret:   "secret",
+		MaxBodySize: 500,
+		Handle: func(ctx context.Context, n *WebhookNotif) error {
+			got = n
+			return nil
+		},
+	}
+	cases := []struct {
+		Header string
+		Value  string
+		Body   string
+		Status int
+	}{
+		{"X-Hub-Signature-256", "sha256=" + hex.EncodeToString(sign256.Sum(nil)), payload, http.StatusOK},
+		{"X-Hub-Signature", "sha1=" + hex.EncodeToString(sign1.Sum(nil)), payload, http.StatusOK},
+		{"X-Hub-Signature-256", "sha256=" + hex.EncodeToString(sign1.Sum(nil)), payload, http.StatusForbidden},
+		{"X-Hub-Signature-256", "sha256=zz", payload, http.StatusForbidden},
+		{"", "", payload, http.StatusForbidden},
+		{"", "", strings.Repeat(" ", 501), http.StatusRequestEntityTooLarge},
+	}
+	for i, tc := range cases {
+		got = nil
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tc.Body))
+		if tc.Header != "" {
+			r.Header.Set(tc.Header, tc.Value)
+		}
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, r)
+		if rec.Code != tc.Status {
+			t.Errorf("case %d: got status %d", i, rec.Code)
+		}
+		if tc.Status == http.StatusOK && (got == nil || got.Object != "page" || len(got.Entry) != 1) {
+			t.Errorf("case %d: got notification %+v", i, got)
+		}
+		if tc.Status != http.StatusOK && got != nil {
+			t.Errorf("case %d: the notification should not have been handled", i)
+		}
+	}
+}
+
+func TestWebhookRouter_Dispatch(t *testing.T) {
+	const payload = `{"object":"page","entry":[{"id":"123","time":1522862162,"changes":[` +
+		`{"field":"leadgen","value":{"leadgen_id":"444","page_id":"123","created_time":1522862162}},` +
+		`{"field":"feed","value":{"item":"comment","verb":"add","from":{"id":"9","name":"Some One"}}},` +
+		`{"field":"location"}]}]}`
+	n := new(WebhookNotif)
+	if err := json.Unmarshal([]byte(payload), n); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	wr := new(WebhookRouter)
+	wr.HandleLeadgen(func(ctx context.Context, entry *WebhookEntry, v *LeadGenEntry) error {
+		got = append(got, "leadgen:"+entry.ID+":"+v.LeadgenID)
+		return nil
+	})
+	wr.HandleFeed(func(ctx context.Context, entry *WebhookEntry, v *FeedValue) error {
+		got = append(got, "feed:"+v.Item+":"+v.From.Name)
+		return nil
+	})
+	wr.Fallback = func(ctx context.Context, entry *WebhookEntry, change *WebhookChange) error {
+		got = append(got, "fallback:"+change.Field)
+		return nil
+	}
+	if err := wr.Dispatch(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"leadgen:123:444", "feed:comment:Some One", "fallback:location"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("got calls %v", got)
+	}
+
+	n.Object = WebhookObjectUser
+	n.Entry[0].Changes = nil
+	n.Entry[0].ChangedFields = []string{"email"}
+	got = nil
+	if err := wr.Dispatch(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "fallback:email" {
+		t.Errorf("got calls %v for changed fields", got)
+	}
+}